@@ -12,16 +12,25 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// initTimeout is the time used to prepare the game, the engine waits longer for the bot name than for a turn
+	initTimeout = 30 * time.Second
+	// turnTimeout is the time used to command the pilots in every turn
+	turnTimeout = 1900 * time.Millisecond
+)
+
 type GameConfig struct {
 	Source   <-chan string
 	Response chan<- string
 	Debug    bool
+	Strategy string
 }
 
 func NewConf(source <-chan string, response chan<- string) GameConfig {
 	return GameConfig{
 		Source:   source,
 		Response: response,
+		Strategy: control.DefaultStrategy,
 	}
 }
 
@@ -53,46 +62,75 @@ func (g Game) Loop() {
 
 	defer g.End()
 
-	conn := hlt.NewConnection(g.BotName, g.Conf.Source, g.Conf.Response)
+	conn := hlt.Handshake(g.Conf.Source, g.Conf.Response)
 	halitedebug.InitializeDefaultCanvas("http://localhost:8888", time.Now().Format("2006-01-02+15:04:05"), g.Conf.Debug)
 
 	log.Print("Game Starts")
 
-	gameMap, _ := conn.UpdateMap()
+	gameMap, _, err := conn.UpdateMap()
+	if err != nil {
+		log.Panicf("Unable to parse initial map: %s", err)
+	}
 	commander := control.NewCommander()
+	commander.Debug = g.Conf.Debug
+	commander.Strategy, err = control.NewStrategy(g.Conf.Strategy)
+	if err != nil {
+		log.Panicf("Unable to start the game: %s", err)
+	}
+	log.Printf("Playing with strategy %s", g.Conf.Strategy)
 
-	gameturn := 1
-	commander.SetMap(gameMap, gameturn)
-	for {
-		var start time.Time
-		gameMap, start = conn.UpdateMap()
-		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*1900)
-
-		PrintDebugEntities(gameMap)
+	prepareStart := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), initTimeout)
+	if err := commander.Prepare(ctx, gameMap); err != nil {
+		log.Errorf("Preparation stopped after %s: %s", time.Since(prepareStart), err)
+	}
+	cancel()
+	log.Printf("Preparation time %s", time.Since(prepareStart))
+	conn.SendName(g.BotName)
 
-		commander.SetMap(gameMap, gameturn)
-		commander.Command(ctx)
-		cancel()
+	gameturn := 1
 
-		//commandQueue := []string{}
+	// The commander keeps the last map, so the parser alternates between two buffers. The buffer only changes
+	// after the commander takes a map, a failed parse must not overwrite the one it kept
+	maps := [2]hlt.Map{}
+	parsed := 0
+	var planner *planning
+	for {
+		gameMap := &maps[parsed%2]
+		start, err := conn.UpdateMapInto(gameMap)
+		if err != nil {
+			log.Errorf("Turn %v skipped, unable to parse map: %s", gameturn, err)
+			conn.SubmitCommands(nil)
+			gameturn++
+			continue
+		}
+		// A planner that ignored the cancellation still owns the commander, the turns are skipped until it returns
+		if planner != nil && planner.Running() {
+			log.Errorf("Turn %v skipped, the planning of a previous turn is still running", gameturn)
+			conn.SubmitCommands(nil)
+			gameturn++
+			continue
+		}
+		// The deadline counts from the moment the map arrives, parsing is part of the turn
+		ctx, cancel := context.WithDeadline(context.Background(), start.Add(turnTimeout))
 
-		//myPlayer := commander.Players()[gameMap.MyID]
-		//myShips := myPlayer.Ships
+		PrintDebugEntities(*gameMap)
 
-		//for i := 0; i < len(myShips); i++ {
-		////shipStart := time.Now()
-		//ship := myShips[i]
-		//if ship.DockingStatus == hlt.UNDOCKED {
-		//commandQueue = append(commandQueue, control.AstarStrategy(ship, *commander))
-		//}
-		////log.Printf("Time for ship %s, total %s", time.Since(shipStart), time.Since(start))
-		//}
+		planner = startPlanning(ctx, commander, *gameMap, gameturn, start)
+		parsed++
 
-		commandQueue := commander.CommandQueue()
-		log.Printf("Turn time %s, avg per ship %f", time.Since(start), time.Since(start).Seconds()/float64(len(commander.Pilots)))
-		log.Printf("Turn %v\n", gameturn)
-		log.Printf("out %v\n", commandQueue)
+		commandQueue, rejected := hlt.ValidateCommands(*gameMap, planner.Commands(ctx))
+		for _, err := range rejected {
+			log.Errorf("Turn %v dropped %s", gameturn, err)
+		}
 		conn.SubmitCommands(commandQueue)
+		cancel()
+		if cause := planner.Stop(turnTimeout); cause != "" {
+			log.Warnf("Turn %v late: %s", gameturn, cause)
+		}
+		planned := commander.Progress().Total
+		log.Printf("Turn time %s, avg per ship %f", time.Since(start), time.Since(start).Seconds()/float64(planned))
+		log.Printf("Turn %v\n", gameturn)
 		halitedebug.Send(gameturn)
 		gameturn++
 	}
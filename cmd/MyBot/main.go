@@ -4,8 +4,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/gorilla/websocket"
+	"github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -15,6 +18,8 @@ func main() {
 	var botName = flag.String("name", "Unity "+UnityVersion, "The name for the bot in local games")
 	var logToFile = flag.Bool("logToFile", false, "log to file, true if server is false")
 	var debugf = flag.Bool("debug", true, "prints to stdout debug information to be used with halite-debug project")
+	var strategy = flag.String("strategy", control.DefaultStrategy, "name of the strategy that plays, one of "+strings.Join(control.StrategyNames(), ", "))
+	var constants = flag.String("constants", "", "JSON file with the game constants, the values sent by the engine take precedence")
 	flag.Parse()
 
 	// TODO: Configure logrus
@@ -29,17 +34,31 @@ func main() {
 		log.SetOutput(f)
 	}
 
+	if *constants != "" {
+		loaded, err := hlt.LoadConstants(*constants)
+		if err != nil {
+			log.Fatalf("Unable to load constants from %s: %s", *constants, err)
+		}
+		hlt.Constants = loaded
+	}
+
+	if _, err := control.NewStrategy(*strategy); err != nil {
+		log.Fatal(err)
+	}
+
 	if *server {
 		log.Print("Running in server mode")
 		ws := WebSocketHandler{
 			Upgrader:  websocket.Upgrader{}, // use default options
 			LogToFile: *logToFile,
 			Debug:     *debugf,
+			Strategy:  *strategy,
 		}
 		ws.CreateServer(*addr)
 	} else {
 		log.Print("Running in local mode")
 		conf := NewLocalConf()
+		conf.Strategy = *strategy
 		game := NewGame(*botName, conf)
 		game.Loop()
 	}
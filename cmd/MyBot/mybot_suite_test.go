@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+
+	halitedebug "github.com/metalblueberry/Halite-debug/pkg/client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = BeforeSuite(func() {
+	halitedebug.InitializeDefaultCanvas("", "", false)
+})
+
+func TestMyBot(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "MyBot Suite")
+}
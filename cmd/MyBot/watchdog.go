@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	log "github.com/sirupsen/logrus"
+)
+
+// stopTimeout is the time that planning has to stop after the commands are submitted before it is reported
+const stopTimeout = 50 * time.Millisecond
+
+// publishGrace is the time that Commands waits for the final publish after the context is done
+const publishGrace = 10 * time.Millisecond
+
+// planning runs the planning of a turn in its own goroutine so the turn can be submitted even if it does not finish in time
+type planning struct {
+	commander *control.Commander
+	done      chan struct{}
+	start     time.Time
+	gc        debug.GCStats
+	// panic is the value recovered from the planning goroutine, it can only be read after done is closed
+	panic interface{}
+}
+
+// startPlanning updates the commander with the map of the turn that started at start and plans it.
+// Both run in the planning goroutine, so a slow update is also bound by the context
+func startPlanning(ctx context.Context, commander *control.Commander, gameMap hlt.Map, turn int, start time.Time) *planning {
+	p := newPlanning(commander, start)
+	p.run(func() {
+		commander.SetMap(gameMap, turn)
+		for _, event := range commander.Events() {
+			log.Printf("Turn %v: %s", turn, event)
+		}
+		commander.Command(ctx)
+	})
+	return p
+}
+
+func newPlanning(commander *control.Commander, start time.Time) *planning {
+	p := &planning{
+		commander: commander,
+		done:      make(chan struct{}),
+		start:     start,
+	}
+	debug.ReadGCStats(&p.gc)
+	return p
+}
+
+// run calls plan in the planning goroutine and recovers it from panics
+func (p *planning) run(plan func()) {
+	go func() {
+		defer close(p.done)
+		defer func() {
+			if r := recover(); r != nil {
+				p.panic = r
+				log.Errorf("Planning failed: %v\n%s", r, debug.Stack())
+			}
+		}()
+		plan()
+	}()
+}
+
+// Running tells whether the planning goroutine has not returned yet, the commander must not be used until it does
+func (p *planning) Running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Commands waits until planning finishes or the context is done and returns the best commands published until then.
+// Once the context is done, planning has publishGrace to publish the commands of the pilots planned so far
+func (p *planning) Commands(ctx context.Context) []hlt.Command {
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		grace := time.NewTimer(publishGrace)
+		defer grace.Stop()
+		select {
+		case <-p.done:
+		case <-grace.C:
+		}
+	}
+	return p.commander.Published()
+}
+
+// Stop waits up to stopTimeout for the planning goroutine to return, the context must be done or about to be.
+// It returns the reason why the turn was late or an empty string if it was on time
+func (p *planning) Stop(turnTimeout time.Duration) string {
+	submitted := time.Now()
+	timeout := time.NewTimer(stopTimeout)
+	defer timeout.Stop()
+	stopped := true
+	select {
+	case <-p.done:
+	case <-timeout.C:
+		stopped = false
+	}
+
+	progress := p.commander.Progress()
+	elapsed := submitted.Sub(p.start)
+	if stopped && p.panic == nil && progress.Finished && elapsed <= turnTimeout {
+		return ""
+	}
+
+	causes := []string{}
+	if !progress.Finished {
+		causes = append(causes, fmt.Sprintf("planning stopped at pilot %d after %d of %d pilots", progress.Pilot, progress.Planned, progress.Total))
+	}
+	if progress.Expanded > 0 {
+		causes = append(causes, fmt.Sprintf("path searches expanded %d nodes in %s", progress.Expanded, progress.Searching))
+	}
+	if !stopped {
+		causes = append(causes, fmt.Sprintf("planning did not stop %s after the commands were submitted", stopTimeout))
+	} else if p.panic != nil {
+		causes = append(causes, fmt.Sprintf("planning panicked: %v", p.panic))
+	}
+	if elapsed > turnTimeout {
+		causes = append(causes, fmt.Sprintf("submitted %s after the turn started", elapsed))
+	}
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+	if collections := gc.NumGC - p.gc.NumGC; collections > 0 {
+		causes = append(causes, fmt.Sprintf("%d garbage collections paused %s", collections, gc.PauseTotal-p.gc.PauseTotal))
+	}
+	return strings.Join(causes, ", ")
+}
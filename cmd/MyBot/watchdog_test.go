@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/simulator"
+)
+
+var _ = Describe("Watchdog", func() {
+	var commander *control.Commander
+
+	BeforeEach(func() {
+		commander = control.NewCommander()
+	})
+
+	It("Should not report a turn planned on time", func() {
+		planner := startPlanning(context.Background(), commander, simulator.Generate(240, 160, 2, 1), 1, time.Now())
+
+		Expect(planner.Commands(context.Background())).NotTo(BeEmpty())
+		Expect(planner.Stop(time.Minute)).To(BeEmpty())
+		Expect(planner.Running()).To(BeFalse())
+	})
+	It("Should report where the planning stopped", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		planner := startPlanning(ctx, commander, simulator.Generate(240, 160, 2, 1), 1, time.Now())
+
+		Expect(planner.Commands(ctx)).To(BeEmpty())
+		Expect(planner.Stop(time.Minute)).To(ContainSubstring("planning stopped at pilot -1 after 0 of"))
+	})
+	It("Should report a turn submitted after the timeout", func() {
+		planner := startPlanning(context.Background(), commander, simulator.Generate(240, 160, 2, 1), 1, time.Now().Add(-time.Minute))
+
+		planner.Commands(context.Background())
+		Expect(planner.Stop(time.Second)).To(ContainSubstring("after the turn started"))
+	})
+	It("Should report a panic in the planning", func() {
+		planner := newPlanning(commander, time.Now())
+		planner.run(func() {
+			panic("no pilots")
+		})
+
+		planner.Commands(context.Background())
+		Expect(planner.Stop(time.Minute)).To(ContainSubstring("planning panicked: no pilots"))
+	})
+	It("Should not wait for a planning that ignores the context", func() {
+		release := make(chan struct{})
+		defer close(release)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		planner := newPlanning(commander, time.Now())
+		planner.run(func() {
+			<-release
+		})
+
+		planner.Commands(ctx)
+		start := time.Now()
+		cause := planner.Stop(time.Minute)
+
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(cause).To(ContainSubstring("planning did not stop"))
+		Expect(planner.Running()).To(BeTrue())
+	})
+})
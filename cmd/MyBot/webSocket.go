@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/websocket"
+	"github.com/metalblueberry/halite-bot/pkg/control"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -11,9 +12,21 @@ type WebSocketHandler struct {
 	Upgrader  websocket.Upgrader
 	LogToFile bool
 	Debug     bool
+	// Strategy is used when the request does not have a strategy query parameter
+	Strategy string
 }
 
 func (ws *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	strategy := r.URL.Query().Get("strategy")
+	if strategy == "" {
+		strategy = ws.Strategy
+	}
+	if _, err := control.NewStrategy(strategy); err != nil {
+		log.Print("strategy:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	socket, err := ws.Upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Print("upgrade:", err)
@@ -28,6 +41,7 @@ func (ws *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	conf := NewConf(source, response)
 	conf.Debug = ws.Debug
+	conf.Strategy = strategy
 	game := NewGame("WSBot", conf)
 
 	go ws.ListenForGameUpdates(response, socket)
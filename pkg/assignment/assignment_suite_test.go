@@ -0,0 +1,13 @@
+package assignment_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAssignment(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Assignment Suite")
+}
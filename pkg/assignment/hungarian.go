@@ -0,0 +1,130 @@
+// Package assignment solves the minimum cost assignment problem with the Hungarian algorithm
+package assignment
+
+import "math"
+
+// Solve assigns every row to a different column minimizing the total cost and returns the
+// column of each row. When there are more rows than columns, the rows left out get -1.
+// cost must be a rectangular matrix without infinite values
+func Solve(cost [][]float64) []int {
+	rows := len(cost)
+	if rows == 0 {
+		return []int{}
+	}
+	columns := len(cost[0])
+	if rows > columns {
+		// Solve the transposed problem, every column gets a row and the rest are left out
+		transposed := make([][]float64, columns)
+		for j := range transposed {
+			transposed[j] = make([]float64, rows)
+			for i := range cost {
+				transposed[j][i] = cost[i][j]
+			}
+		}
+		result := make([]int, rows)
+		for i := range result {
+			result[i] = -1
+		}
+		for column, row := range hungarian(transposed) {
+			result[row] = column
+		}
+		return result
+	}
+	return hungarian(cost)
+}
+
+// SolveWithCapacity works like Solve but column j can receive up to capacity[j] rows
+func SolveWithCapacity(cost [][]float64, capacity []int) []int {
+	slots := []int{}
+	for column, c := range capacity {
+		for i := 0; i < c; i++ {
+			slots = append(slots, column)
+		}
+	}
+
+	expanded := make([][]float64, len(cost))
+	for i, row := range cost {
+		expanded[i] = make([]float64, len(slots))
+		for slot, column := range slots {
+			expanded[i][slot] = row[column]
+		}
+	}
+	if len(slots) == 0 {
+		result := make([]int, len(cost))
+		for i := range result {
+			result[i] = -1
+		}
+		return result
+	}
+
+	result := Solve(expanded)
+	for i, slot := range result {
+		if slot >= 0 {
+			result[i] = slots[slot]
+		}
+	}
+	return result
+}
+
+// hungarian solves the problem for rows <= columns using potentials, in O(rows^2 * columns).
+// Indexes start at 1 so 0 can be used as a sentinel for the free row
+func hungarian(cost [][]float64) []int {
+	rows, columns := len(cost), len(cost[0])
+	u := make([]float64, rows+1)
+	v := make([]float64, columns+1)
+	// match[j] is the row assigned to column j
+	match := make([]int, columns+1)
+	way := make([]int, columns+1)
+	minv := make([]float64, columns+1)
+	used := make([]bool, columns+1)
+
+	for i := 1; i <= rows; i++ {
+		match[0] = i
+		j0 := 0
+		for j := range minv {
+			minv[j] = math.Inf(1)
+			used[j] = false
+		}
+		for {
+			used[j0] = true
+			i0, delta, j1 := match[j0], math.Inf(1), 0
+			for j := 1; j <= columns; j++ {
+				if used[j] {
+					continue
+				}
+				current := cost[i0-1][j-1] - u[i0] - v[j]
+				if current < minv[j] {
+					minv[j], way[j] = current, j0
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			for j := 0; j <= columns; j++ {
+				if used[j] {
+					u[match[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if match[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			match[j0] = match[j1]
+			j0 = j1
+		}
+	}
+
+	result := make([]int, rows)
+	for j := 1; j <= columns; j++ {
+		if match[j] != 0 {
+			result[match[j]-1] = j - 1
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package assignment_test
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/assignment"
+)
+
+// bruteForce returns the minimum total cost trying every assignment of rows to distinct columns
+func bruteForce(cost [][]float64, row int, used []bool) float64 {
+	if row == len(cost) {
+		return 0
+	}
+	best := math.Inf(1)
+	assigned := 0
+	for column := range cost[row] {
+		if used[column] {
+			continue
+		}
+		assigned++
+		used[column] = true
+		best = math.Min(best, cost[row][column]+bruteForce(cost, row+1, used))
+		used[column] = false
+	}
+	if assigned == 0 {
+		// More rows than columns, the row is left out
+		return bruteForce(cost, row+1, used)
+	}
+	// Leaving a row out is only allowed when the columns are exhausted
+	free := 0
+	for _, u := range used {
+		if !u {
+			free++
+		}
+	}
+	if len(cost)-row > free {
+		best = math.Min(best, bruteForce(cost, row+1, used))
+	}
+	return best
+}
+
+func total(cost [][]float64, result []int) float64 {
+	sum := 0.0
+	for row, column := range result {
+		if column >= 0 {
+			sum += cost[row][column]
+		}
+	}
+	return sum
+}
+
+func randomMatrix(random *rand.Rand, rows, columns int) [][]float64 {
+	cost := make([][]float64, rows)
+	for i := range cost {
+		cost[i] = make([]float64, columns)
+		for j := range cost[i] {
+			cost[i][j] = float64(random.Intn(100))
+		}
+	}
+	return cost
+}
+
+var _ = Describe("Hungarian", func() {
+	It("Should solve a simple case", func() {
+		cost := [][]float64{
+			{4, 1, 3},
+			{2, 0, 5},
+			{3, 2, 2},
+		}
+		Expect(Solve(cost)).To(Equal([]int{1, 0, 2}))
+	})
+	It("Should handle empty matrices", func() {
+		Expect(Solve(nil)).To(BeEmpty())
+	})
+	It("Should find the optimal assignment", func() {
+		random := rand.New(rand.NewSource(1))
+		for i := 0; i < 100; i++ {
+			rows, columns := 1+random.Intn(6), 1+random.Intn(6)
+			cost := randomMatrix(random, rows, columns)
+			result := Solve(cost)
+
+			seen := map[int]bool{}
+			left := 0
+			for _, column := range result {
+				if column < 0 {
+					left++
+					continue
+				}
+				Expect(seen[column]).To(BeFalse())
+				seen[column] = true
+			}
+			Expect(left).To(Equal(int(math.Max(0, float64(rows-columns)))))
+			Expect(total(cost, result)).To(Equal(bruteForce(cost, 0, make([]bool, columns))))
+		}
+	})
+	It("Should respect the capacity of the columns", func() {
+		cost := [][]float64{
+			{1, 10},
+			{1, 10},
+			{1, 10},
+		}
+		result := SolveWithCapacity(cost, []int{2, 1})
+		Expect(result).To(ConsistOf(0, 0, 1))
+
+		result = SolveWithCapacity(cost, []int{1, 0})
+		Expect(result).To(ConsistOf(0, -1, -1))
+
+		Expect(SolveWithCapacity(cost, []int{0, 0})).To(Equal([]int{-1, -1, -1}))
+	})
+})
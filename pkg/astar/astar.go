@@ -1,6 +1,10 @@
 package astar
 
-import "container/heap"
+import (
+	"container/heap"
+	"context"
+	"time"
+)
 
 // astar is an A* pathfinding implementation.
 
@@ -26,6 +30,20 @@ type node struct {
 	open   bool
 	closed bool
 	index  int
+	// unchecked tells that the link to the parent must be verified when the node is expanded
+	unchecked bool
+}
+
+// nodeStore keeps the A* data of the nodes touched by a search.
+type nodeStore interface {
+	// get gets the Pather object wrapped in a node, instantiating if required.
+	get(p Pather) *node
+}
+
+// NeighborAppender can be implemented by Pather nodes to append their neighbors to a
+// slice that is reused between expansions instead of allocating a new one.
+type NeighborAppender interface {
+	AppendPathNeighbors(neighbors []Pather) []Pather
 }
 
 // nodeMap is a collection of nodes keyed by Pather nodes for quick reference.
@@ -50,7 +68,11 @@ func reverse(p []Pather) {
 }
 
 func unwindPath(curr *node) []Pather {
-	p := []Pather{}
+	length := 0
+	for n := curr; n != nil; n = n.parent {
+		length++
+	}
+	p := make([]Pather, 0, length)
 	for curr != nil {
 		p = append(p, curr.pather)
 		curr = curr.parent
@@ -58,18 +80,120 @@ func unwindPath(curr *node) []Pather {
 	return p
 }
 
+// Stats describes the work done by a search
+type Stats struct {
+	// Expanded is the number of nodes taken from the open set
+	Expanded int
+	Elapsed  time.Duration
+}
+
+// contextCheckInterval is the number of expanded nodes between checks of the context
+const contextCheckInterval = 64
+
 // Path calculates a short path and the distance between the two Pather nodes.
 //
 // If no path is found, found will be false.
 func Path(from, to Pather, iterations int) (path []Pather, distance float64, found bool, bestPath []Pather) {
-	nm := nodeMap{}
-	nq := &priorityQueue{}
+	path, distance, found, bestPath, _ = PathContext(context.Background(), from, to, iterations)
+	return path, distance, found, bestPath
+}
+
+// PathContext works like Path but it also stops when the context is done. When the search stops
+// before reaching the goal, found is false and bestPath goes to the node closest to the goal
+func PathContext(ctx context.Context, from, to Pather, iterations int) (path []Pather, distance float64, found bool, bestPath []Pather, stats Stats) {
+	return search(ctx, from, to, iterations, nodeMap{}, &priorityQueue{}, &buffers{}, nil)
+}
+
+// Successor is a node reachable from the node being expanded and the cost to get there
+type Successor struct {
+	Node Pather
+	Cost float64
+	// From is the node the cost is measured from, nil for the node being expanded. Any-angle searches
+	// like Theta* use it to link the successor directly to an ancestor that has line of sight
+	From Pather
+	// Unchecked links are verified with Verifier when the successor is expanded, see Verifier
+	Unchecked bool
+}
+
+// Expander replaces the direct neighbors of the nodes as the successors of a search. It is used by
+// searches that skip nodes, like Jump Point Search, where the successors depend on the parent
+type Expander interface {
+	// Expand appends to successors the nodes reachable from current. Parent is nil for the start node, cost and
+	// parentCost are the costs of the paths to current and to its parent
+	Expand(current, parent, goal Pather, cost, parentCost float64, successors []Successor) []Successor
+}
+
+// Verifier is implemented by expanders that return Unchecked successors, like Lazy Theta*, to delay expensive checks
+// until the nodes are expanded. When the link does not hold, the node is linked to its cheapest closed neighbor instead
+type Verifier interface {
+	// Verify reports if the node can be reached directly from the parent with the cost of the successor
+	Verify(parent, node Pather) bool
+}
+
+// buffers holds the slices reused between the expansions of a search
+type buffers struct {
+	neighbors  []Pather
+	successors []Successor
+}
+
+func (b *buffers) expand(current *node, goal Pather, expander Expander) []Successor {
+	b.successors = b.successors[:0]
+	if expander != nil {
+		var parent Pather
+		parentCost := 0.0
+		if current.parent != nil {
+			parent, parentCost = current.parent.pather, current.parent.cost
+		}
+		b.successors = expander.Expand(current.pather, parent, goal, current.cost, parentCost, b.successors)
+		return b.successors
+	}
+
+	for _, neighbor := range b.appendNeighbors(current.pather) {
+		b.successors = append(b.successors, Successor{Node: neighbor, Cost: current.pather.PathNeighborCost(neighbor)})
+	}
+	return b.successors
+}
+
+func (b *buffers) appendNeighbors(p Pather) []Pather {
+	if appender, ok := p.(NeighborAppender); ok {
+		b.neighbors = appender.AppendPathNeighbors(b.neighbors[:0])
+		return b.neighbors
+	}
+	return p.PathNeighbors()
+}
+
+// relink links the node to the closed neighbor that reaches it with the lowest cost. The node keeps the
+// unchecked link if none of its neighbors is closed, it only happens when they were reopened by cheaper paths
+func (b *buffers) relink(current *node, nodes nodeStore) {
+	var parent *node
+	cost := 0.0
+	for _, neighbor := range b.appendNeighbors(current.pather) {
+		neighborNode := nodes.get(neighbor)
+		if !neighborNode.closed {
+			continue
+		}
+		if neighborCost := neighborNode.cost + neighbor.PathNeighborCost(current.pather); parent == nil || neighborCost < cost {
+			parent, cost = neighborNode, neighborCost
+		}
+	}
+	if parent != nil {
+		current.parent, current.cost = parent, cost
+	}
+}
+
+func search(ctx context.Context, from, to Pather, iterations int, nodes nodeStore, nq *priorityQueue, buffers *buffers, expander Expander) (path []Pather, distance float64, found bool, bestPath []Pather, stats Stats) {
+	start := time.Now()
+	defer func() {
+		stats.Elapsed = time.Since(start)
+	}()
+
 	heap.Init(nq)
-	fromNode := nm.get(from)
+	fromNode := nodes.get(from)
 	fromNode.rank = from.PathEstimatedCost(to)
 	fromNode.open = true
 	heap.Push(nq, fromNode)
 	bestNode := fromNode
+	toNode := nodes.get(to)
 
 	for {
 		if nq.Len() == 0 {
@@ -80,20 +204,33 @@ func Path(from, to Pather, iterations int) (path []Pather, distance float64, fou
 		current := heap.Pop(nq).(*node)
 		current.open = false
 		current.closed = true
+		if current.unchecked {
+			current.unchecked = false
+			if !expander.(Verifier).Verify(current.parent.pather, current.pather) {
+				buffers.relink(current, nodes)
+			}
+		}
 
-		if current == nm.get(to) || iterations == 0 {
-			// Found a path to the goal or run out of iterations
+		// The goal is found even when it is popped after the search runs out of iterations or the context is done
+		found := current == toNode
+		if found || iterations == 0 || stats.Expanded%contextCheckInterval == 0 && ctx.Err() != nil {
 			p := unwindPath(current)
 			bestPath := unwindPath(bestNode)
 			reverse(p)
 			reverse(bestPath)
-			return p, current.cost, iterations != 0, bestPath
+			return p, current.cost, found, bestPath, stats
 		}
 		iterations--
+		stats.Expanded++
 
-		for _, neighbor := range current.pather.PathNeighbors() {
-			cost := current.cost + current.pather.PathNeighborCost(neighbor)
-			neighborNode := nm.get(neighbor)
+		for _, successor := range buffers.expand(current, to, expander) {
+			neighbor := successor.Node
+			parent := current
+			if successor.From != nil {
+				parent = nodes.get(successor.From)
+			}
+			cost := parent.cost + successor.Cost
+			neighborNode := nodes.get(neighbor)
 			if cost < neighborNode.cost {
 				if neighborNode.open {
 					heap.Remove(nq, neighborNode.index)
@@ -105,7 +242,8 @@ func Path(from, to Pather, iterations int) (path []Pather, distance float64, fou
 				neighborNode.cost = cost
 				neighborNode.open = true
 				neighborNode.rank = cost + neighbor.PathEstimatedCost(to)
-				neighborNode.parent = current
+				neighborNode.parent = parent
+				neighborNode.unchecked = successor.Unchecked
 				if bestNode.rank-bestNode.cost >= neighborNode.rank-neighborNode.cost {
 					bestNode = neighborNode
 				}
@@ -4,7 +4,10 @@ package astar
 // implementation.  testPath is used to check the calculated path distance is
 // what we're expecting.
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 // testPath takes a string encoded world, decodes it, calculates a path and
 // checks the expected distance matches.  An expectedDist of -1 expects that no
@@ -98,6 +101,61 @@ func TestRiverSwimmer(t *testing.T) {
 `, t, 11)
 }
 
+// TestCancelledContext checks that a cancelled search returns the best partial path.
+func TestCancelledContext(t *testing.T) {
+	world := ParseWorld(`
+.F........T.
+`)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, found, best, stats := PathContext(ctx, world.From(), world.To(), -1)
+	if found {
+		t.Fatal("Expected the search to stop before finding the path")
+	}
+	if len(best) == 0 || best[0] != world.From() {
+		t.Fatalf("Expected the best path to start at the origin, got %v", best)
+	}
+	if stats.Expanded != 0 {
+		t.Fatalf("Expected no expanded nodes but got %d", stats.Expanded)
+	}
+}
+
+// TestGoalAtTheLimit checks that the goal is found when it is popped after the last iteration or with the
+// context done.
+func TestGoalAtTheLimit(t *testing.T) {
+	world := ParseWorld(`
+.F........T.
+`)
+	_, dist, found, _, _ := PathContext(context.Background(), world.From(), world.To(), 9)
+	if !found || dist != 9 {
+		t.Fatalf("Expected a path of 9 with 9 iterations but got %v, found %v", dist, found)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, found, _, _ = PathContext(ctx, world.From(), world.From(), -1)
+	if !found {
+		t.Fatal("Expected the origin to be found with the context done")
+	}
+}
+
+// TestStats checks that the expanded nodes are counted.
+func TestStats(t *testing.T) {
+	world := ParseWorld(`
+.F........T.
+`)
+	_, dist, found, _, stats := PathContext(context.Background(), world.From(), world.To(), -1)
+	if !found || dist != 9 {
+		t.Fatalf("Expected a path of 9 but got %v, found %v", dist, found)
+	}
+	if stats.Expanded != 9 {
+		t.Fatalf("Expected 9 expanded nodes but got %d", stats.Expanded)
+	}
+	if stats.Elapsed <= 0 {
+		t.Fatal("Expected the elapsed time to be measured")
+	}
+}
+
 func BenchmarkLarge(b *testing.B) {
 	world := ParseWorld(`
 F............................~.................................................
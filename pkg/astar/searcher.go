@@ -0,0 +1,73 @@
+package astar
+
+import (
+	"context"
+)
+
+// Indexed is implemented by Pather nodes that have a dense index in their graph, like the tiles of a grid.
+// Indexes go from 0 to the number of nodes of the graph minus 1
+type Indexed interface {
+	Pather
+	PathIndex() int
+}
+
+// Searcher runs A* searches on graphs of Indexed nodes. The A* data of the nodes is stored in arrays
+// that are reused between searches, a generation counter tells which entries belong to the current one
+// so they never need to be cleared.
+//
+// A Searcher is not safe for concurrent use
+type Searcher struct {
+	nodes      []node
+	generation []uint32
+	current    uint32
+	queue      priorityQueue
+	buffers    buffers
+}
+
+// NewSearcher creates a Searcher for graphs with up to size nodes
+func NewSearcher(size int) *Searcher {
+	return &Searcher{
+		nodes:      make([]node, size),
+		generation: make([]uint32, size),
+		buffers: buffers{
+			neighbors:  make([]Pather, 0, 8),
+			successors: make([]Successor, 0, 8),
+		},
+	}
+}
+
+// Size returns the number of nodes that the Searcher can hold
+func (s *Searcher) Size() int {
+	return len(s.nodes)
+}
+
+// Path works like PathContext, all the nodes of the graph must implement Indexed.
+// Only the returned paths are allocated
+func (s *Searcher) Path(ctx context.Context, from, to Indexed, iterations int) (path []Pather, distance float64, found bool, bestPath []Pather, stats Stats) {
+	return s.PathWith(ctx, nil, from, to, iterations)
+}
+
+// PathWith works like Path but the successors of the nodes are generated by the expander.
+// The returned paths only contain the expanded nodes
+func (s *Searcher) PathWith(ctx context.Context, expander Expander, from, to Indexed, iterations int) (path []Pather, distance float64, found bool, bestPath []Pather, stats Stats) {
+	s.current++
+	if s.current == 0 {
+		// The counter wrapped around, old generations could be taken as the current one
+		for i := range s.generation {
+			s.generation[i] = 0
+		}
+		s.current = 1
+	}
+	s.queue = s.queue[:0]
+	return search(ctx, from, to, iterations, s, &s.queue, &s.buffers, expander)
+}
+
+func (s *Searcher) get(p Pather) *node {
+	index := p.(Indexed).PathIndex()
+	n := &s.nodes[index]
+	if s.generation[index] != s.current {
+		s.generation[index] = s.current
+		*n = node{pather: p}
+	}
+	return n
+}
@@ -0,0 +1,171 @@
+package control
+
+import (
+	"github.com/metalblueberry/halite-bot/pkg/assignment"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// TargetKind classifies the candidates of the assignment
+type TargetKind int
+
+const (
+	// Settle targets are planets with free docking spots
+	Settle TargetKind = iota
+	// Raid targets are enemy docked ships
+	Raid
+	// Intercept targets are enemy ships close to our planets
+	Intercept
+	// Defend targets are positions between our planets and the closest enemy
+	Defend
+)
+
+// Extra cost, in turns, of each kind of target. Negative values make pilots travel further for them
+var targetKindCost = map[TargetKind]float64{
+	Settle:    0,
+	Raid:      2,
+	Intercept: -3,
+	Defend:    4,
+}
+
+const (
+	// damagedCost is added to fights for pilots that are not healthy enough to win them
+	damagedCost = 20
+	// clusterBonus is subtracted from the planets of the clusters we own, they are easier to defend
+	clusterBonus = 2
+)
+
+// interceptRange is the distance to the surface of our planets at which enemies become targets, 3 turns at full speed
+func interceptRange() float64 {
+	return 3 * hlt.Constants.MaxSpeed
+}
+
+// Target is a candidate objective for the pilots
+type Target struct {
+	Kind TargetKind
+	// Position is what the pilot goes for, a *PlanetStats, an hlt.Ship or a position
+	Position twoD.Positioner
+	// Capacity is the number of pilots that the target can receive
+	Capacity int
+}
+
+// Candidates returns the targets that pilots can be assigned to this turn
+func (c *Commander) Candidates() []Target {
+	targets := []Target{}
+	enemy := func(entity hlt.Entitier) bool {
+		return entity.Owner() != c.gameMap.MyID
+	}
+	intercepted := make(map[int]bool)
+
+	for _, planet := range c.gameMap.Planets {
+		stats := c.Planets[planet.ID()]
+		free := int(planet.NumDockingSpots - planet.NumDockedShips)
+		if planet.Owned != 0 && planet.Owner() != c.gameMap.MyID {
+			continue
+		}
+		if free > 0 {
+			targets = append(targets, Target{Kind: Settle, Position: stats, Capacity: free})
+		}
+		if planet.Owned == 0 {
+			continue
+		}
+
+		_, _, r := planet.Circle()
+		for _, entity := range c.shipIndex.Within(planet, r+interceptRange()) {
+			// An enemy close to several of our planets is a single target
+			if ship := *entity.(*hlt.Ship); enemy(entity) && !intercepted[ship.ID()] {
+				intercepted[ship.ID()] = true
+				targets = append(targets, Target{Kind: Intercept, Position: ship, Capacity: 2})
+			}
+		}
+		if nearest := c.shipIndex.Nearest(planet, 1, enemy); len(nearest) > 0 {
+			position := twoD.ClosestPointTo(nearest[0], planet, 2)
+			targets = append(targets, Target{Kind: Defend, Position: position, Capacity: 1})
+		}
+	}
+
+	for _, player := range c.gameMap.Players {
+		if player.ID == c.gameMap.MyID {
+			continue
+		}
+		for _, ship := range player.Ships {
+			if ship.DockingStatus != hlt.UNDOCKED {
+				targets = append(targets, Target{Kind: Raid, Position: ship, Capacity: 1})
+			}
+		}
+	}
+	return targets
+}
+
+// Cost estimates how bad it is for the pilot to go for the target, in turns
+func (c *Commander) Cost(pilot *Pilot, target Target) float64 {
+	cost := c.travelDistance(pilot, target.Position)/hlt.Constants.MaxSpeed + targetKindCost[target.Kind]
+	if planet, ok := target.Position.(*PlanetStats); ok && target.Kind == Settle && c.ownsCluster(planet) {
+		cost -= clusterBonus
+	}
+	if target.Kind != Settle && pilot.Health() <= hlt.Constants.MaxShipHealth/4 {
+		cost += damagedCost
+	}
+	return cost
+}
+
+// Assign solves the global assignment of undocked pilots to targets for the current turn.
+// Pilots with an OpeningPlan keep their planet and take its docking spots
+func (c *Commander) Assign() map[int]twoD.Positioner {
+	assigned := make(map[int]twoD.Positioner)
+	planned := make(map[int]int)
+	pilots := []*Pilot{}
+	for _, pilot := range c.GetPilotsByHealth() {
+		if pilot.DockingStatus != hlt.UNDOCKED {
+			continue
+		}
+		if planet := c.OpeningPlan(pilot.Ship); planet != nil {
+			assigned[pilot.ID()] = planet
+			planned[planet.ID()]++
+			continue
+		}
+		pilots = append(pilots, pilot)
+	}
+	targets := c.Candidates()
+
+	// When there are more pilots than spots in the targets, the solver leaves out
+	// the pilots that would add more cost
+	capacity := make([]int, len(targets))
+	for i, target := range targets {
+		capacity[i] = target.Capacity
+		if planet, ok := target.Position.(*PlanetStats); ok && target.Kind == Settle {
+			capacity[i] -= planned[planet.ID()]
+		}
+	}
+
+	cost := make([][]float64, len(pilots))
+	for i, pilot := range pilots {
+		cost[i] = make([]float64, len(targets))
+		for j, target := range targets {
+			cost[i][j] = c.Cost(pilot, target)
+		}
+	}
+
+	for i, column := range assignment.SolveWithCapacity(cost, capacity) {
+		if column >= 0 {
+			assigned[pilots[i].ID()] = targets[column].Position
+		}
+	}
+	return assigned
+}
+
+// assignmentStrategy is the DefaultStrategy, it sends the pilots to the targets of the global assignment,
+// solved once per turn
+type assignmentStrategy struct {
+	greedyStrategy
+	turn    int
+	targets map[int]twoD.Positioner
+}
+
+func (s *assignmentStrategy) Target(c *Commander, pilot *Pilot) twoD.Positioner {
+	if s.targets == nil || s.turn != c.currentTurn {
+		s.targets = c.Assign()
+		s.turn = c.currentTurn
+	}
+	return s.targets[pilot.ID()]
+}
@@ -0,0 +1,96 @@
+package control_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+var _ = Describe("Assignment", func() {
+	var commander *Commander
+
+	BeforeEach(func() {
+		commander = NewCommander()
+	})
+
+	It("Should not send more pilots to a planet than free spots", func() {
+		// newMap has a planet with 2 spots at 80,80
+		commander.SetMap(newMap(newShip(70, 70, 0, 0), newShip(71, 70, 0, 1), newShip(72, 70, 0, 2)), 1)
+
+		assigned := commander.Assign()
+		settlers := 0
+		for _, target := range assigned {
+			if _, ok := target.(*PlanetStats); ok {
+				settlers++
+			}
+		}
+		Expect(settlers).To(Equal(2))
+	})
+	It("Should list the candidates with their capacity", func() {
+		docked := newShip(85, 80, 1, 3)
+		docked.DockingStatus = hlt.DOCKED
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), docked), 1)
+
+		candidates := commander.Candidates()
+		Expect(candidates).To(HaveLen(2))
+		Expect(candidates[0].Kind).To(Equal(Settle))
+		Expect(candidates[0].Capacity).To(Equal(2))
+		Expect(candidates[1].Kind).To(Equal(Raid))
+		Expect(candidates[1].Position).To(Equal(docked))
+	})
+	It("Should intercept enemies close to our planets", func() {
+		mine := newMap(newShip(60, 60, 0, 0), newShip(10, 10, 0, 1), newShip(90, 70, 1, 2))
+		mine.Planets[0] = hlt.Planet{
+			Entity:          hlt.NewEntity(80, 80, 5, 1275, 0, 0),
+			NumDockingSpots: 2,
+			NumDockedShips:  2,
+			Owned:           1,
+		}
+		commander.SetMap(mine, 1)
+
+		assigned := commander.Assign()
+		Expect(assigned[0]).To(Equal(mine.Ships[2]))
+	})
+	It("Should intercept an enemy close to several of our planets once", func() {
+		mine := newMap(newShip(60, 60, 0, 0), newShip(10, 10, 0, 1), newShip(80, 70, 1, 2))
+		mine.Planets = []hlt.Planet{
+			{Entity: hlt.NewEntity(70, 80, 3, 765, 0, 0), NumDockingSpots: 1, NumDockedShips: 1, Owned: 1},
+			{Entity: hlt.NewEntity(90, 80, 3, 765, 0, 1), NumDockingSpots: 1, NumDockedShips: 1, Owned: 1},
+		}
+		commander.SetMap(mine, 1)
+
+		intercepts := 0
+		for _, candidate := range commander.Candidates() {
+			if candidate.Kind == Intercept {
+				intercepts++
+			}
+		}
+		Expect(intercepts).To(Equal(1))
+	})
+	It("Should keep the opening plan of the pilots", func() {
+		gameMap := newMap(newShip(70, 70, 0, 0), newShip(71, 70, 0, 1), newShip(72, 70, 0, 2), newShip(10, 10, 1, 3))
+		Expect(commander.Prepare(context.Background(), gameMap)).To(Succeed())
+		commander.SetMap(gameMap, 1)
+
+		assigned := commander.Assign()
+		planned := 0
+		for _, pilot := range commander.Pilots {
+			if planet := commander.OpeningPlan(pilot.Ship); planet != nil {
+				Expect(assigned[pilot.ID()]).To(Equal(planet))
+				planned++
+			}
+		}
+		Expect(planned).To(BeNumerically(">", 0))
+		settlers := 0
+		for _, target := range assigned {
+			if _, ok := target.(*PlanetStats); ok {
+				settlers++
+			}
+		}
+		Expect(settlers).To(Equal(2))
+	})
+})
@@ -0,0 +1,193 @@
+package control
+
+import (
+	"math"
+
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// maxCollisionChecks limits the times the plans are checked again after fixing the commands
+const maxCollisionChecks = 10
+
+// avoidRotations are the angles, in degrees, tried when a move must be fixed
+var avoidRotations = []int{0, 15, -15, 30, -30, 45, -45, 60, -60, 90, -90}
+
+// Collision is a crash predicted for the current plan
+type Collision struct {
+	// Pilot is the one that should change its command to avoid the crash
+	Pilot *Pilot
+	// With is the ship that Pilot would hit, it can be another pilot or an enemy.
+	// It is empty when the pilot hits a planet
+	With hlt.Ship
+	// Planet is the planet that Pilot would hit, if any
+	Planet *PlanetStats
+	// Time is the moment of the turn, between 0 and 1, at which they touch
+	Time float64
+}
+
+// predictEnemies guesses the enemy moves assuming they repeat the displacement of the previous turn
+func (c *Commander) predictEnemies() {
+	c.enemyMotion = make(map[int]twoD.SweptCircle)
+	for _, event := range c.events {
+		moved, ok := event.(hlt.ShipMoved)
+		if !ok || moved.Ship.Owner() == c.gameMap.MyID {
+			continue
+		}
+		c.enemyMotion[moved.Ship.ID()] = twoD.NewSweptCircle(moved.Ship, moved.DX, moved.DY)
+	}
+}
+
+func (c *Commander) motion(ship hlt.Ship) twoD.SweptCircle {
+	if ship.Owner() == c.gameMap.MyID {
+		return c.Pilots[ship.ID()].Motion()
+	}
+	if motion, exist := c.enemyMotion[ship.ID()]; exist {
+		return motion
+	}
+	return ship.Motion(nil)
+}
+
+// shipsInReach returns the ships that the pilot can touch this turn.
+// Two ships can only touch if they are closer than what both can move in a turn
+func (c *Commander) shipsInReach(pilot *Pilot) []hlt.Entitier {
+	return c.shipIndex.Within(pilot, 2*hlt.Constants.MaxSpeed+2*hlt.Constants.ShipRadius)
+}
+
+// hitPlanet returns the first planet that the motion touches
+func (c *Commander) hitPlanet(pilot *Pilot, motion twoD.SweptCircle) (*PlanetStats, float64, bool) {
+	for _, entity := range c.planetIndex.Within(pilot, hlt.Constants.MaxSpeed+hlt.Constants.ShipRadius) {
+		planet := entity.(*PlanetStats)
+		if t, collide := twoD.CollisionTime(motion, twoD.NewSweptCircle(planet, 0, 0)); collide {
+			return planet, t, true
+		}
+	}
+	return nil, 0, false
+}
+
+// PredictCollisions checks the current commands of the pilots against each other, against the planets
+// and against the predicted enemy motion. Thrust commands already hold the rounded integer angle
+// that the engine uses, so the prediction matches the real move. Every pair of ships is reported once
+func (c *Commander) PredictCollisions() []Collision {
+	collisions := []Collision{}
+
+	pilots := c.GetPilotsByHealth()
+	rank := make(map[int]int, len(pilots))
+	for i, pilot := range pilots {
+		rank[pilot.ID()] = i
+	}
+
+	for _, pilot := range pilots {
+		motion := pilot.Motion()
+		if pilot.Moving() {
+			if planet, t, collide := c.hitPlanet(pilot, motion); collide {
+				collisions = append(collisions, Collision{Pilot: pilot, Planet: planet, Time: t})
+			}
+		}
+
+		for _, entity := range c.shipsInReach(pilot) {
+			other := entity.(*hlt.Ship)
+			if other.ID() == pilot.ID() {
+				continue
+			}
+			mine := other.Owner() == c.gameMap.MyID
+			if mine && rank[other.ID()] > rank[pilot.ID()] {
+				continue
+			}
+
+			t, collide := twoD.CollisionTime(motion, c.motion(*other))
+			if !collide {
+				continue
+			}
+
+			// The pilot planned later gives way unless it is not moving
+			collision := Collision{Pilot: pilot, With: *other, Time: t}
+			if !pilot.Moving() {
+				if !mine || !c.Pilots[other.ID()].Moving() {
+					continue
+				}
+				collision = Collision{Pilot: c.Pilots[other.ID()], With: pilot.Ship, Time: t}
+			}
+			collisions = append(collisions, collision)
+		}
+	}
+	return collisions
+}
+
+// safe reports if the pilot can do the motion without touching planets, other ships or leaving the map
+func (c *Commander) safe(pilot *Pilot, motion twoD.SweptCircle) bool {
+	x, y := motion.At(1).Position()
+	if x-motion.R < 0 || y-motion.R < 0 || x+motion.R > float64(c.gameMap.Width) || y+motion.R > float64(c.gameMap.Height) {
+		return false
+	}
+	if _, _, collide := c.hitPlanet(pilot, motion); collide {
+		return false
+	}
+	for _, entity := range c.shipsInReach(pilot) {
+		other := entity.(*hlt.Ship)
+		if other.ID() == pilot.ID() {
+			continue
+		}
+		if _, collide := twoD.CollisionTime(motion, c.motion(*other)); collide {
+			return false
+		}
+	}
+	return true
+}
+
+// avoid returns the safe thrust that keeps most of the progress of the current one.
+// It tries shorter and rotated moves and returns nil if the pilot must stay still
+func (c *Commander) avoid(pilot *Pilot) hlt.Command {
+	thrust, ok := pilot.Command.(hlt.ThrustCommand)
+	if !ok {
+		return nil
+	}
+
+	var best hlt.Command
+	bestProgress := 0.0
+	for _, rotation := range avoidRotations {
+		for magnitude := thrust.Magnitude; magnitude > 0; magnitude-- {
+			if rotation == 0 && magnitude == thrust.Magnitude {
+				continue
+			}
+			progress := float64(magnitude) * math.Cos(twoD.DegToRad(float64(rotation)))
+			if progress <= bestProgress {
+				continue
+			}
+			candidate := hlt.ThrustCommand{
+				ShipID:    thrust.ShipID,
+				Magnitude: magnitude,
+				Angle:     ((thrust.Angle+rotation)%360 + 360) % 360,
+			}
+			if c.safe(pilot, pilot.Ship.Motion(candidate)) {
+				best, bestProgress = candidate, progress
+				break
+			}
+		}
+	}
+	return best
+}
+
+// ResolveCollisions fixes the commands of the pilots that would crash by shortening, rotating
+// or cancelling their moves. Every fix can create new crashes, so the plan is checked again until it is safe
+func (c *Commander) ResolveCollisions() {
+	for i := 0; i < maxCollisionChecks; i++ {
+		collisions := c.PredictCollisions()
+		if len(collisions) == 0 {
+			return
+		}
+		fixed := make(map[*Pilot]bool)
+		for _, collision := range collisions {
+			if fixed[collision.Pilot] {
+				continue
+			}
+			fixed[collision.Pilot] = true
+			collision.Pilot.Command = c.avoid(collision.Pilot)
+		}
+	}
+
+	// Stop the remaining pilots, the ones that are still moving can only make it worse
+	for _, collision := range c.PredictCollisions() {
+		collision.Pilot.Command = nil
+	}
+}
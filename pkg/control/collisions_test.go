@@ -0,0 +1,107 @@
+package control_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+// newMap creates a 100x100 map with a planet far from the ships
+func newMap(ships ...hlt.Ship) hlt.Map {
+	planet := hlt.Planet{Entity: hlt.NewEntity(80, 80, 5, 1275, 0, 0), NumDockingSpots: 2}
+	gameMap := hlt.Map{
+		Width:   100,
+		Height:  100,
+		Players: []hlt.Player{{ID: 0}, {ID: 1}},
+		Ships:   make(map[int]hlt.Ship),
+		Planets: []hlt.Planet{planet},
+	}
+	for _, ship := range ships {
+		player := &gameMap.Players[ship.Owner()]
+		player.Ships = append(player.Ships, ship)
+		gameMap.Ships[ship.ID()] = ship
+		gameMap.Entities = append(gameMap.Entities, ship.Entity)
+	}
+	gameMap.Entities = append(gameMap.Entities, planet.Entity)
+	return gameMap
+}
+
+func newShip(x, y float64, owner, id int) hlt.Ship {
+	return hlt.Ship{Entity: hlt.NewEntity(x, y, 0.5, 255, owner, id)}
+}
+
+var _ = Describe("Collisions", func() {
+	var commander *Commander
+
+	BeforeEach(func() {
+		commander = NewCommander()
+	})
+
+	It("Should predict crashes between pilots", func() {
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(17, 10, 0, 1), newShip(10, 30, 0, 2)), 1)
+		commander.Pilots[0].Command = hlt.ThrustCommand{ShipID: 0, Magnitude: 4, Angle: 0}
+		commander.Pilots[1].Command = hlt.ThrustCommand{ShipID: 1, Magnitude: 4, Angle: 180}
+		commander.Pilots[2].Command = hlt.ThrustCommand{ShipID: 2, Magnitude: 7, Angle: 0}
+
+		collisions := commander.PredictCollisions()
+		Expect(collisions).To(HaveLen(1))
+		Expect(collisions[0].Time).To(BeNumerically("~", 0.75, 0.001))
+		Expect([]int{collisions[0].Pilot.ID(), collisions[0].With.ID()}).To(ConsistOf(0, 1))
+	})
+	It("Should make the moving pilot give way to a still one", func() {
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(15, 10, 0, 1)), 1)
+		commander.Pilots[0].Command = hlt.ThrustCommand{ShipID: 0, Magnitude: 7, Angle: 0}
+
+		collisions := commander.PredictCollisions()
+		Expect(collisions).To(HaveLen(1))
+		Expect(collisions[0].Pilot.ID()).To(Equal(0))
+		Expect(collisions[0].With.ID()).To(Equal(1))
+	})
+	It("Should use the previous displacement of the enemies", func() {
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(23, 16, 1, 1)), 1)
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(20, 13, 1, 1)), 2)
+
+		commander.Pilots[0].Command = hlt.ThrustCommand{ShipID: 0, Magnitude: 7, Angle: 180}
+		Expect(commander.PredictCollisions()).To(BeEmpty())
+
+		commander.Pilots[0].Command = hlt.ThrustCommand{ShipID: 0, Magnitude: 7, Angle: 0}
+		collisions := commander.PredictCollisions()
+		Expect(collisions).To(HaveLen(1))
+		Expect(collisions[0].With.ID()).To(Equal(1))
+	})
+
+	Describe("Resolution", func() {
+		It("Should keep both pilots moving when there is room", func() {
+			commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(17, 10, 0, 1)), 1)
+			commander.Pilots[0].Command = hlt.ThrustCommand{ShipID: 0, Magnitude: 4, Angle: 0}
+			commander.Pilots[1].Command = hlt.ThrustCommand{ShipID: 1, Magnitude: 4, Angle: 180}
+
+			commander.ResolveCollisions()
+			Expect(commander.PredictCollisions()).To(BeEmpty())
+			Expect(commander.Pilots[0].Moving()).To(BeTrue())
+			Expect(commander.Pilots[1].Moving()).To(BeTrue())
+		})
+		It("Should avoid planets", func() {
+			commander.SetMap(newMap(newShip(70, 80, 0, 0)), 1)
+			commander.Pilots[0].Command = hlt.ThrustCommand{ShipID: 0, Magnitude: 7, Angle: 0}
+			Expect(commander.PredictCollisions()).To(HaveLen(1))
+			Expect(commander.PredictCollisions()[0].Planet.ID()).To(Equal(0))
+
+			commander.ResolveCollisions()
+			Expect(commander.PredictCollisions()).To(BeEmpty())
+			Expect(commander.Pilots[0].Moving()).To(BeTrue())
+			x, _ := commander.Pilots[0].Motion().At(1).Position()
+			Expect(x).To(BeNumerically(">", 73))
+		})
+		It("Should cancel the move when there is no room", func() {
+			commander.SetMap(newMap(newShip(1, 1, 0, 0), newShip(2.1, 1, 0, 1), newShip(1, 2.1, 0, 2), newShip(2.1, 2.1, 0, 3)), 1)
+			commander.Pilots[0].Command = hlt.ThrustCommand{ShipID: 0, Magnitude: 7, Angle: 45}
+
+			commander.ResolveCollisions()
+			Expect(commander.PredictCollisions()).To(BeEmpty())
+			Expect(commander.Pilots[0].Command).To(BeNil())
+		})
+	})
+})
@@ -10,22 +10,64 @@ import (
 	"github.com/metalblueberry/halite-bot/pkg/twoD"
 )
 
+const (
+	// hierarchyClusterSize is the size of the clusters of the path hierarchy
+	hierarchyClusterSize = 16
+	// hierarchyDistance is the distance in tiles from which the paths are planned on the hierarchy
+	hierarchyDistance = 2 * hierarchyClusterSize
+)
+
+// reservationWindow is the number of turns that the pilots reserve their route for, the pilots planned later fly around it
+const reservationWindow = 3
+
 // Commander persist between turns and manage the different ships
 type Commander struct {
 	gameMap hlt.Map
 
 	currentTurn int
 
-	Grid    *navigation.Grid
-	Planets map[int]*PlanetStats
-	Pilots  map[int]*Pilot
+	Grid *navigation.Grid
+	// GridScale is the number of tiles of the grid per unit of the map, NewCommander sets 1
+	GridScale float64
+	Planets   map[int]*PlanetStats
+	Pilots    map[int]*Pilot
+
+	// Strategy takes the decisions of the pilots, NewCommander sets the DefaultStrategy
+	Strategy Strategy
+	// Debug draws the influence heatmap in the debug canvas every turn, it is expensive on big grids
+	Debug bool
+
+	events   []hlt.Event
+	handlers []EventHandler
+
+	planetIndex *hlt.SpatialIndex
+	shipIndex   *hlt.SpatialIndex
+	enemyMotion map[int]twoD.SweptCircle
+
+	// Clusters of planets of the map, they are filled by Prepare
+	Clusters [][]int
+	routes   map[routeKey]Route
+	opening  map[int]*PlanetStats
+	// flowFields are the shared flow fields by planet ID, see refreshFlowFields
+	flowFields map[int]*navigation.FlowField
+	// hierarchy is the HPA* graph of the grid, it is updated every turn where the grid changed
+	hierarchy *navigation.Hierarchy
+	// searchers are the A* buffers handed to the grid of every turn
+	searchers *navigation.Searchers
+	// weighters combine the layers of the grid with the roleWeights
+	weighters map[Role]navigation.Weighter
+
+	plan plan
 }
 
+// EventHandler receives the events found when a new map is set, after pilots and planets are updated
+type EventHandler func(event hlt.Event)
+
 func (c *Commander) PreCalculations() {
 
 	for _, player := range c.gameMap.Players {
 		for _, ship := range player.Ships {
-			closestPlanet := c.GetPlanetsByDistance(ship)[0]
+			closestPlanet := c.planetIndex.Nearest(ship, 1, nil)[0].(*PlanetStats)
 			closestPlanet.InOrbitShips = append(closestPlanet.InOrbitShips, ship)
 
 			if ship.Owner() == c.gameMap.MyID {
@@ -35,58 +77,78 @@ func (c *Commander) PreCalculations() {
 	}
 }
 
+// Command plans the commands of the pilots until it finishes or the context is done. The best commands
+// found so far are published regularly, so other goroutines can submit them with Published at any time
 func (c *Commander) Command(ctx context.Context) {
 
 	c.PreCalculations()
 
-	for _, pilot := range c.GetPilotsByHealth() {
+	pilots := c.GetPilotsByHealth()
+	c.startPlan(len(pilots))
+	defer func() {
+		c.ResolveCollisions()
+		c.publish(ctx.Err() == nil)
+	}()
+
+	for _, pilot := range c.Pilots {
+		pilot.Command = nil
+	}
+	for i, pilot := range pilots {
 		if ctx.Err() != nil {
 			return
 		}
-		if pilot.DockingStatus != hlt.UNDOCKED {
-			continue
-		}
-
-		target := c.FindTarget(pilot)
+		c.publishIfDue()
+		c.planning(pilot, i)
+		route := c.commandPilot(ctx, pilot)
+		c.Grid.Reservations.ReserveRoute(pilot.ID(), append([]navigation.Positioner{pilot}, route...)...)
+	}
+}
 
-		if target == nil {
-			continue
-		}
+// commandPilot sets the command of the pilot and returns the points it will fly through in the next turns,
+// nothing when it stays where it is
+func (c *Commander) commandPilot(ctx context.Context, pilot *Pilot) []navigation.Positioner {
+	if pilot.DockingStatus == hlt.DOCKED && c.Strategy.Undock(c, pilot) {
+		pilot.Command = pilot.Undock()
+		pilot.Transition(Undocking)
+		return nil
+	}
+	if pilot.DockingStatus != hlt.UNDOCKED {
+		return nil
+	}
 
-		switch targetType := target.(type) {
-		case *PlanetStats:
-			targetType.PilotsInTheWay += 1.0
-			if pilot.CanDock(targetType.Planet) {
-				pilot.Command = pilot.Dock(targetType.Planet)
-				continue
-			}
-		default:
-			if pilot.DockingStatus != hlt.UNDOCKED {
-				pilot.Command = pilot.Undock()
-				continue
-			}
-		}
+	target := c.commit(pilot, c.Strategy.Target(c, pilot))
 
-		//log.Printf("position %s", position)
+	if target == nil {
+		return nil
+	}
 
-		path, err := c.CalculatePath(pilot, target)
-		if err != nil {
-			continue
+	if planet, ok := target.(*PlanetStats); ok {
+		planet.PilotsInTheWay += 1.0
+		if pilot.CanDock(planet.Planet) && c.Strategy.Dock(c, pilot, planet) {
+			pilot.Command = pilot.Dock(planet.Planet)
+			pilot.Transition(Docking)
+			return nil
 		}
+	}
 
-		turnpath := GetPathForTurn(c.gameMap, pilot, path, 8)
-		for _, step := range turnpath {
-			step.Type = navigation.Blocked
-		}
+	path, err := c.CalculatePath(ctx, pilot, target)
+	if err != nil {
+		return nil
+	}
 
-		if len(turnpath) == 0 {
-			continue
-		}
+	destination, rest, ok := GetWaypointForTurn(c.gameMap, pilot, path)
+	if !ok {
+		return nil
+	}
 
-		halitedebug.Line(twoD.NewLine(pilot, turnpath[len(turnpath)-1]), "nextStep")
+	halitedebug.Line(twoD.NewLine(pilot, destination), "nextStep")
 
-		pilot.Command = pilot.NavigateBasic(turnpath[len(turnpath)-1])
+	pilot.Command = pilot.NavigateBasic(destination)
+	route := []navigation.Positioner{destination}
+	for _, waypoint := range rest {
+		route = append(route, waypoint)
 	}
+	return route
 }
 
 type byHealth []*Pilot
@@ -98,15 +160,22 @@ func (a byHealth) Less(i, j int) bool {
 }
 
 func NewCommander() *Commander {
+	strategy, _ := NewStrategy(DefaultStrategy)
 	return &Commander{
-		Planets: make(map[int]*PlanetStats),
-		Pilots:  make(map[int]*Pilot),
+		GridScale: 1,
+		searchers: &navigation.Searchers{},
+		Planets:   make(map[int]*PlanetStats),
+		Pilots:    make(map[int]*Pilot),
+		Strategy:  strategy,
 	}
 }
 
-func (c *Commander) CommandQueue() []string {
-	commandQueue := make([]string, 0, len(c.Pilots))
+func (c *Commander) CommandQueue() []hlt.Command {
+	commandQueue := make([]hlt.Command, 0, len(c.Pilots))
 	for _, pilot := range c.Pilots {
+		if pilot.Command == nil {
+			continue
+		}
 		commandQueue = append(commandQueue, pilot.Command)
 	}
 	return commandQueue
@@ -120,13 +189,38 @@ func (c *Commander) Me() hlt.Player {
 	return c.gameMap.Players[c.gameMap.MyID]
 }
 
+// SetMap updates the commander with the map of a new turn. The commands published for the previous
+// turn are dropped first, they are not valid for the new map
 func (c *Commander) SetMap(Map hlt.Map, turn int) {
+	c.startPlan(0)
+	c.events = hlt.Diff(c.gameMap, Map)
 	c.currentTurn = turn
 	c.gameMap = Map
+	c.gameMap.BuildIndex()
 
 	c.findPilotShips()
 	c.findPlanetsStats()
 	c.generateGrid()
+	c.buildIndexes()
+	c.refreshCommitments()
+	c.refreshFlowFields()
+	c.predictEnemies()
+
+	for _, event := range c.events {
+		for _, handler := range c.handlers {
+			handler(event)
+		}
+	}
+}
+
+// Subscribe registers a handler that is called for every event of the following turns
+func (c *Commander) Subscribe(handler EventHandler) {
+	c.handlers = append(c.handlers, handler)
+}
+
+// Events returns what happened since the previous map
+func (c *Commander) Events() []hlt.Event {
+	return c.events
 }
 
 func (c *Commander) GetPilots() []*Pilot {
@@ -159,6 +253,9 @@ func (c *Commander) GetPlanetsByImportance(pilot *Pilot) []*PlanetStats {
 	}
 
 	sort.Sort(sort.Reverse(byValue(planets)))
+	if c.currentTurn <= openingTurns {
+		sortByTerritory(planets)
+	}
 	return planets
 }
 
@@ -205,6 +302,7 @@ func (c *Commander) findPilotShips() {
 		}
 
 		pilot.SetShip(ship)
+		pilot.syncState()
 		pilot.lastTurnUpdated = c.currentTurn
 	}
 	c.removeDeadPilots()
@@ -218,19 +316,49 @@ func (c *Commander) removeDeadPilots() {
 	}
 }
 
+// buildIndexes creates the indexes used to find planets and ships by proximity.
+// They hold pointers to the PlanetStats and to the ships of the current map
+func (c *Commander) buildIndexes() {
+	planets := make([]hlt.Entitier, 0, len(c.gameMap.Planets))
+	for _, planet := range c.gameMap.Planets {
+		planets = append(planets, c.Planets[planet.ID()])
+	}
+	c.planetIndex = hlt.NewSpatialIndex(c.gameMap.Width, c.gameMap.Height, hlt.IndexCellSize, planets)
+
+	ships := make([]hlt.Entitier, 0, len(c.gameMap.Ships))
+	for _, player := range c.gameMap.Players {
+		for i := range player.Ships {
+			ships = append(ships, &player.Ships[i])
+		}
+	}
+	c.shipIndex = hlt.NewSpatialIndex(c.gameMap.Width, c.gameMap.Height, hlt.IndexCellSize, ships)
+}
+
 func (c *Commander) generateGrid() {
-	c.Grid = navigation.NewGrid(c.gameMap.Width, c.gameMap.Height)
+	c.Grid = navigation.NewScaledGrid(c.gameMap.Width, c.gameMap.Height, c.GridScale)
+	c.Grid.Searchers = c.searchers
+	c.Grid.Pathfinder = navigation.ThetaStar
+	c.Grid.Reservations = navigation.NewReservations(c.Grid, reservationWindow, hlt.Constants.MaxSpeed)
 	for _, player := range c.gameMap.Players {
 		for _, ship := range player.Ships {
 			x, y := ship.Position()
-			if player.ID == c.gameMap.MyID {
-				c.Grid.PaintShip(x, y, 0)
-			} else {
-				c.Grid.PaintShip(x, y, 5)
-			}
+			c.Grid.PaintShip(x, y, 0)
 		}
 	}
 	for _, planet := range c.gameMap.Planets {
 		c.Grid.PaintPlanet(planet.Circle())
 	}
+	c.generateInfluence()
+	if c.Debug {
+		c.drawInfluence()
+	}
+	c.weighters = make(map[Role]navigation.Weighter)
+	for role, weights := range roleWeights {
+		c.weighters[role] = c.Grid.Weighting(weights)
+	}
+	if c.hierarchy == nil {
+		c.hierarchy = navigation.NewHierarchy(c.Grid, hierarchyClusterSize)
+	} else {
+		c.hierarchy.Update(c.Grid)
+	}
 }
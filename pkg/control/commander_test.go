@@ -1,11 +1,29 @@
 package control_test
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo"
-	//. "github.com/onsi/gomega"
-	//. "github.com/metalblueberry/halite-bot/pkg/control"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/simulator"
 )
 
+func commanderBot(commander *Commander) simulator.Bot {
+	turn := 0
+	return func(gameMap hlt.Map) []string {
+		if turn == 0 {
+			Expect(commander.Prepare(context.Background(), gameMap)).To(Succeed())
+		}
+		turn++
+		commander.SetMap(gameMap, turn)
+		commander.Command(context.Background())
+		return []string{hlt.SerializeCommands(commander.CommandQueue())}
+	}
+}
+
 var _ = Describe("Test Planet Stats", func() {
 	var ()
 
@@ -13,3 +31,49 @@ var _ = Describe("Test Planet Stats", func() {
 	})
 
 })
+
+var _ = Describe("Commander", func() {
+	It("Should win a game against an idle bot", func() {
+		sim := simulator.New(simulator.Generate(240, 160, 2, 1))
+		idle := func(gameMap hlt.Map) []string { return nil }
+
+		winner, err := sim.Play(commanderBot(NewCommander()), idle)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(winner).To(Equal(0))
+	})
+	It("Should notify the events of every turn", func() {
+		sim := simulator.New(simulator.Generate(240, 160, 2, 1))
+		commander := NewCommander()
+		events := []hlt.Event{}
+		commander.Subscribe(func(event hlt.Event) {
+			events = append(events, event)
+		})
+
+		commander.SetMap(sim.Map(0), 1)
+		Expect(events).To(HaveLen(6))
+		Expect(events[0]).To(BeAssignableToTypeOf(hlt.ShipSpawned{}))
+
+		events = events[:0]
+		commander.Command(context.Background())
+		Expect(sim.Step([]string{hlt.SerializeCommands(commander.CommandQueue())})).To(Succeed())
+		commander.SetMap(sim.Map(0), 2)
+		Expect(events).ToNot(BeEmpty())
+		Expect(events).To(Equal(commander.Events()))
+		for _, event := range events {
+			Expect(event).To(BeAssignableToTypeOf(hlt.ShipMoved{}))
+		}
+	})
+	It("Should reserve the tiles of every pilot for the next turns", func() {
+		sim := simulator.New(simulator.Generate(240, 160, 2, 1))
+		commander := NewCommander()
+		commander.SetMap(sim.Map(0), 1)
+
+		commander.Command(context.Background())
+
+		for _, pilot := range commander.Pilots {
+			id, ok := commander.Grid.Reservations.Owner(1, commander.Grid.GetTile(pilot.Position()))
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(pilot.ID()))
+		}
+	})
+})
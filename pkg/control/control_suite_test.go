@@ -3,10 +3,15 @@ package control_test
 import (
 	"testing"
 
+	halitedebug "github.com/metalblueberry/Halite-debug/pkg/client"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+var _ = BeforeSuite(func() {
+	halitedebug.InitializeDefaultCanvas("", "", false)
+})
+
 func TestControl(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Control Suite")
@@ -0,0 +1,66 @@
+package control
+
+import (
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+const (
+	// flowFieldPilots is the number of pilots heading to the same planet that makes it worth a flow field
+	flowFieldPilots = 3
+	// flowFieldChange is the fraction of tiles that must become or stop being obstacles before a cached flow field is
+	// built again, see FlowField.Changed
+	flowFieldChange = 0.02
+)
+
+// refreshFlowFields builds the flow fields of the planets shared by several pilots. Fields from previous turns
+// are kept until the grid changes significantly and dropped when the pilots go elsewhere
+func (c *Commander) refreshFlowFields() {
+	demand := make(map[int]int)
+	for _, pilot := range c.Pilots {
+		if id, ok := flowTarget(pilot.target); ok && pilot.DockingStatus == hlt.UNDOCKED {
+			demand[id]++
+		}
+	}
+
+	fields := make(map[int]*navigation.FlowField)
+	for id, pilots := range demand {
+		planet, exist := c.Planets[id]
+		if pilots < flowFieldPilots || !exist {
+			continue
+		}
+		field, cached := c.flowFields[id]
+		if !cached || field.Changed(c.Grid) > flowFieldChange {
+			tiles := c.dockRangeTiles(planet)
+			targets := make([]navigation.Positioner, len(tiles))
+			for i, tile := range tiles {
+				targets[i] = tile
+			}
+			field = c.Grid.FlowField(targets...)
+		}
+		fields[id] = field
+	}
+	c.flowFields = fields
+}
+
+// FlowField returns the flow field to the dock range of the target planet, or the planet of a docked ship.
+// It is nil when the target is not shared by enough pilots
+func (c *Commander) FlowField(target twoD.Positioner) *navigation.FlowField {
+	id, ok := flowTarget(target)
+	if !ok {
+		return nil
+	}
+	return c.flowFields[id]
+}
+
+// flowTarget returns the planet that the pilots heading to the target get close to
+func flowTarget(target twoD.Positioner) (int, bool) {
+	switch target := target.(type) {
+	case *PlanetStats:
+		return target.ID(), true
+	case hlt.Ship:
+		return target.PlanetID, target.DockingStatus != hlt.UNDOCKED
+	}
+	return 0, false
+}
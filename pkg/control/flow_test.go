@@ -0,0 +1,101 @@
+package control_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+var _ = Describe("Flow fields", func() {
+	var commander *Commander
+	var gameMap hlt.Map
+
+	BeforeEach(func() {
+		commander = NewCommander()
+		gameMap = newMap(newShip(10, 10, 0, 0), newShip(10, 30, 0, 1), newShip(30, 10, 0, 2))
+		commander.SetMap(gameMap, 1)
+	})
+	commit := func(ids ...int) *PlanetStats {
+		planet := commander.Planets[gameMap.Planets[0].ID()]
+		for _, id := range ids {
+			commander.Pilots[id].Commit(Settler, planet, 1)
+		}
+		return planet
+	}
+
+	It("Should build a field for the planets shared by several pilots", func() {
+		planet := commit(0, 1, 2)
+		commander.SetMap(gameMap, 2)
+
+		Expect(commander.FlowField(planet)).NotTo(BeNil())
+
+		path, err := commander.CalculatePath(context.Background(), commander.Pilots[0], planet)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(path)).To(BeNumerically(">", 1))
+		Expect(path[0]).To(Equal(commander.Grid.GetTile(commander.Pilots[0].Position())))
+		Expect(twoD.Distance(path[len(path)-1], planet)).To(BeNumerically("<", twoD.Distance(path[0], planet)-3*hlt.Constants.MaxSpeed))
+	})
+	It("Should search the path when the field crosses a reserved tile", func() {
+		planet := commit(0, 1, 2)
+		commander.SetMap(gameMap, 2)
+		grid := commander.Grid
+		flow := commander.FlowField(planet).Path(grid.GetTile(commander.Pilots[0].Position()), 5)
+		for turn := 1; turn <= grid.Reservations.Window; turn++ {
+			grid.Reservations.Reserve(turn, flow[3], commander.Pilots[1].ID())
+		}
+
+		path, err := commander.CalculatePath(context.Background(), commander.Pilots[0], planet)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(path)).To(BeNumerically(">", 1))
+		Expect(grid.Reservations.Reserved(path)).To(BeFalse())
+	})
+	It("Should search the path when the enemy fire on the field changed", func() {
+		planet := commit(0, 1, 2)
+		commander.SetMap(gameMap, 2)
+		field := commander.FlowField(planet)
+
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(10, 30, 0, 1), newShip(30, 10, 0, 2), newShip(14, 14, 1, 3)), 3)
+		path, err := commander.CalculatePath(context.Background(), commander.Pilots[0], planet)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commander.FlowField(planet)).To(BeIdenticalTo(field))
+		flow := field.Path(path[0], len(path)-1)
+		same := len(flow) == len(path)
+		for i := 0; same && i < len(path); i++ {
+			same = flow[i] == path[i]
+		}
+		Expect(same).To(BeFalse())
+	})
+	It("Should not build fields for the planets of a few pilots", func() {
+		planet := commit(0, 1)
+		commander.SetMap(gameMap, 2)
+
+		Expect(commander.FlowField(planet)).To(BeNil())
+	})
+	It("Should keep the field while the grid does not change", func() {
+		planet := commit(0, 1, 2)
+		commander.SetMap(gameMap, 2)
+		field := commander.FlowField(planet)
+
+		commander.SetMap(gameMap, 3)
+
+		Expect(commander.FlowField(planet)).To(BeIdenticalTo(field))
+	})
+	It("Should keep the field while the ships move", func() {
+		gameMap = newMap(newShip(10, 10, 0, 0), newShip(10, 30, 0, 1), newShip(30, 10, 0, 2), newShip(60, 40, 1, 3))
+		commander.SetMap(gameMap, 1)
+		planet := commit(0, 1, 2)
+		commander.SetMap(gameMap, 2)
+		field := commander.FlowField(planet)
+
+		commander.SetMap(newMap(newShip(14, 12, 0, 0), newShip(13, 34, 0, 1), newShip(34, 13, 0, 2), newShip(55, 40, 1, 3)), 3)
+
+		Expect(commander.FlowField(planet)).To(BeIdenticalTo(field))
+	})
+})
@@ -0,0 +1,66 @@
+package control
+
+import (
+	"fmt"
+	"math"
+
+	halitedebug "github.com/metalblueberry/Halite-debug/pkg/client"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+const (
+	// heatmapStep is the distance between the influence samples drawn in the debug canvas
+	heatmapStep = 2
+	// heatmapLevels is the number of classes used to color the heatmap, influence above it uses the last one
+	heatmapLevels = 5
+)
+
+// shipStrength is the fire power of a ship for the next turn. Docked ships can not shoot and
+// ships with the weapon cooling down are less dangerous
+func shipStrength(ship hlt.Ship) float64 {
+	if ship.DockingStatus != hlt.UNDOCKED {
+		return 0
+	}
+	return ship.Health() / hlt.Constants.MaxShipHealth / (1 + ship.WeaponCooldown)
+}
+
+// shipReach returns the distance at which a ship can shoot without moving and after one move at full speed
+func shipReach(ship hlt.Ship) (fire, reach float64) {
+	_, _, r := ship.Circle()
+	fire = hlt.Constants.WeaponRadius + r + hlt.Constants.ShipRadius
+	return fire, fire + hlt.Constants.MaxSpeed
+}
+
+// generateInfluence fills the influence layer of the grid with the ships of every player
+func (c *Commander) generateInfluence() {
+	for _, player := range c.gameMap.Players {
+		for _, ship := range player.Ships {
+			x, y := ship.Position()
+			fire, reach := shipReach(ship)
+			c.Grid.Influence.AddShip(x, y, shipStrength(ship), fire, reach, player.ID != c.gameMap.MyID)
+		}
+	}
+}
+
+// Contested reports if the enemies can bring as much fire power as us to the docking area of the planet
+func (c *Commander) Contested(planet *PlanetStats) bool {
+	x, y, r := planet.Circle()
+	return c.Grid.Influence.Contested(x, y, r+hlt.Constants.DockRadius+hlt.Constants.ShipRadius)
+}
+
+// drawInfluence exports the influence layer to the debug canvas as a heatmap.
+// Every sample has the class of its side and a level from 1 to heatmapLevels
+func (c *Commander) drawInfluence() {
+	for _, point := range c.Grid.Influence.Heatmap(heatmapStep) {
+		if point.Enemy > 0 {
+			halitedebug.Circle(point, "influence", "enemy", heatLevel(point.Enemy))
+		}
+		if point.Friendly > 0 {
+			halitedebug.Circle(point, "influence", "friendly", heatLevel(point.Friendly))
+		}
+	}
+}
+
+func heatLevel(value float64) string {
+	return fmt.Sprintf("heat%d", int(math.Min(math.Ceil(value), heatmapLevels)))
+}
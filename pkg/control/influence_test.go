@@ -0,0 +1,44 @@
+package control_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+var _ = Describe("Influence", func() {
+	var commander *Commander
+
+	BeforeEach(func() {
+		commander = NewCommander()
+	})
+
+	It("Should mark planets with enemies around as contested", func() {
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(80, 95, 1, 1)), 1)
+
+		Expect(commander.Contested(commander.Planets[0])).To(BeTrue())
+	})
+	It("Should not mark planets as contested when we are stronger", func() {
+		commander.SetMap(newMap(newShip(80, 66, 0, 0), newShip(81, 66, 0, 2), newShip(80, 95, 1, 1)), 1)
+
+		Expect(commander.Contested(commander.Planets[0])).To(BeFalse())
+	})
+	It("Should ignore enemies that can not shoot", func() {
+		enemy := newShip(80, 95, 1, 1)
+		enemy.DockingStatus = hlt.DOCKED
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), enemy), 1)
+
+		Expect(commander.Contested(commander.Planets[0])).To(BeFalse())
+		Expect(commander.Grid.Influence.EnemyAt(80, 95)).To(Equal(0.0))
+	})
+	It("Should weaken ships with the weapon cooling down", func() {
+		enemy := newShip(50, 50, 1, 1)
+		enemy.WeaponCooldown = 1
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), enemy), 1)
+
+		Expect(commander.Grid.Influence.EnemyAt(50, 50)).To(Equal(0.5))
+		Expect(commander.Grid.Influence.FriendlyAt(10, 10)).To(Equal(1.0))
+	})
+})
@@ -7,10 +7,14 @@ import (
 
 type Pilot struct {
 	hlt.Ship
-	Command         string
+	Command         hlt.Command
 	ClosestPlanet   *PlanetStats
 	lastTurnUpdated int
-	target          twoD.Positioner
+
+	Role          Role
+	State         PilotState
+	target        twoD.Positioner
+	committedTurn int
 }
 
 func NewPilot() *Pilot {
@@ -20,3 +24,14 @@ func NewPilot() *Pilot {
 func (pilot *Pilot) SetShip(ship hlt.Ship) {
 	pilot.Ship = ship
 }
+
+// Motion returns the movement of the pilot with its current command
+func (pilot *Pilot) Motion() twoD.SweptCircle {
+	return pilot.Ship.Motion(pilot.Command)
+}
+
+// Moving reports if the current command is a thrust
+func (pilot *Pilot) Moving() bool {
+	thrust, ok := pilot.Command.(hlt.ThrustCommand)
+	return ok && thrust.Magnitude > 0
+}
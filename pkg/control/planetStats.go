@@ -10,8 +10,13 @@ type PlanetStats struct {
 	FlyingTo        []*Pilot
 	lastTurnUpdated int
 
+	// StaticValue is the advantage in turns to reach the planet at the start of the game, see partitionTerritory
 	StaticValue float64
 	Value       float64
+	// Arrival is the earliest turn that each player can reach the docking range at the start of the game
+	Arrival []int
+	// Cluster is the index of the group of close planets in Commander.Clusters
+	Cluster int
 	//Distance    float64
 
 	InOrbitShips []hlt.Ship
@@ -35,11 +40,12 @@ func (stats *PlanetStats) SetPlanet(planet hlt.Planet) {
 	stats.PilotsInTheWay = 0
 }
 
+// setValueToImportance values the planet by its health relative to a full one, radius times BASE_SHIP_HEALTH
 func (stats *PlanetStats) setValueToImportance(pilot *Pilot) {
 	_, _, radius := stats.Circle()
 
-	stats.Value = stats.Health() / (radius * 255) *
-		(radius - stats.PilotsInTheWay - twoD.Distance(stats, pilot)/hlt.Constants["MAX_SPEED"].(float64))
+	stats.Value = stats.Health() / (radius * hlt.Constants.BaseShipHealth) *
+		(radius - stats.PilotsInTheWay - twoD.Distance(stats, pilot)/hlt.Constants.MaxSpeed)
 }
 
 func (stats *PlanetStats) setValueToDistance(pos twoD.Positioner) {
@@ -0,0 +1,101 @@
+package control
+
+import (
+	"sync"
+	"time"
+
+	"github.com/metalblueberry/halite-bot/pkg/astar"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+// publishInterval is the time between the command sets published while planning
+const publishInterval = 50 * time.Millisecond
+
+// Progress tells how far the planning of the current turn went
+type Progress struct {
+	Planned, Total int
+	// Pilot is the ID of the pilot being planned, -1 before the first one
+	Pilot    int
+	Finished bool
+	// Expanded is the number of nodes expanded by the path searches and Searching the time spent on them
+	Expanded  int
+	Searching time.Duration
+}
+
+// plan holds the commands published by Command, it can be read from other goroutines
+type plan struct {
+	mu          sync.Mutex
+	published   []hlt.Command
+	progress    Progress
+	lastPublish time.Time
+}
+
+// Published returns the best command set found so far in the current turn. It is safe to call
+// while Command is running, the commands are collision free up to the pilots planned at publish time
+func (c *Commander) Published() []hlt.Command {
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	return append([]hlt.Command{}, c.plan.published...)
+}
+
+// Progress returns how far the planning of the current turn went, it is safe to call while Command is running
+func (c *Commander) Progress() Progress {
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	return c.plan.progress
+}
+
+// startPlan drops the commands published in the previous turn
+func (c *Commander) startPlan(total int) {
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	c.plan.published = []hlt.Command{}
+	c.plan.progress = Progress{Total: total, Pilot: -1}
+	c.plan.lastPublish = time.Now()
+}
+
+// planning records the pilot that is going to be planned
+func (c *Commander) planning(pilot *Pilot, planned int) {
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	c.plan.progress.Pilot = pilot.ID()
+	c.plan.progress.Planned = planned
+}
+
+// recordSearch adds the work of a path search to the progress
+func (c *Commander) recordSearch(stats astar.Stats) {
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	c.plan.progress.Expanded += stats.Expanded
+	c.plan.progress.Searching += stats.Elapsed
+}
+
+// publishIfDue publishes the commands planned so far once every publishInterval. The collisions are resolved
+// for the published set only, the pilots keep their commands so the rest of the plan does not depend on the timing
+func (c *Commander) publishIfDue() {
+	if time.Since(c.plan.lastPublish) < publishInterval {
+		return
+	}
+	planned := make(map[*Pilot]hlt.Command, len(c.Pilots))
+	for _, pilot := range c.Pilots {
+		planned[pilot] = pilot.Command
+	}
+	c.ResolveCollisions()
+	c.publish(false)
+	for pilot, command := range planned {
+		pilot.Command = command
+	}
+}
+
+// publish makes the current commands available to Published
+func (c *Commander) publish(finished bool) {
+	commands := c.CommandQueue()
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	c.plan.published = commands
+	c.plan.lastPublish = time.Now()
+	if finished {
+		c.plan.progress.Planned = c.plan.progress.Total
+		c.plan.progress.Finished = true
+	}
+}
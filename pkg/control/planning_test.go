@@ -0,0 +1,113 @@
+package control_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/simulator"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// slowStrategy sends the pilots east and takes delay to decide the target of the first one.
+// It records the command of the first pilot when the second one is planned
+type slowStrategy struct {
+	delay time.Duration
+	first hlt.Command
+}
+
+func (s *slowStrategy) Target(c *Commander, pilot *Pilot) twoD.Positioner {
+	if pilot.ID() == 0 {
+		time.Sleep(s.delay)
+	} else {
+		s.first = c.Pilots[0].Command
+	}
+	x, y := pilot.Position()
+	return twoD.NewPosition(x+30, y)
+}
+
+func (s *slowStrategy) Dock(c *Commander, pilot *Pilot, planet *PlanetStats) bool {
+	return false
+}
+
+func (s *slowStrategy) Undock(c *Commander, pilot *Pilot) bool {
+	return false
+}
+
+var _ = Describe("Planning", func() {
+	var commander *Commander
+
+	BeforeEach(func() {
+		commander = NewCommander()
+		commander.SetMap(simulator.Generate(240, 160, 2, 1), 1)
+	})
+
+	It("Should publish the commands when planning finishes", func() {
+		commander.Command(context.Background())
+
+		Expect(commander.Published()).To(ConsistOf(commander.CommandQueue()))
+		progress := commander.Progress()
+		Expect(progress.Finished).To(BeTrue())
+		Expect(progress.Planned).To(Equal(progress.Total))
+		Expect(progress.Total).To(Equal(len(commander.Pilots)))
+		Expect(progress.Expanded).To(BeNumerically(">", 0))
+		Expect(progress.Searching).To(BeNumerically(">", 0))
+	})
+	It("Should publish an empty set when the context is already done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		commander.Command(ctx)
+
+		Expect(commander.Published()).To(BeEmpty())
+		progress := commander.Progress()
+		Expect(progress.Finished).To(BeFalse())
+		Expect(progress.Planned).To(Equal(0))
+		Expect(progress.Pilot).To(Equal(-1))
+	})
+	It("Should allow reading the published commands while planning", func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			commander.Command(context.Background())
+		}()
+		for finished := false; !finished; {
+			select {
+			case <-done:
+				finished = true
+			default:
+				Expect(len(commander.Published())).To(BeNumerically("<=", len(commander.Pilots)))
+			}
+		}
+		Expect(commander.Progress().Finished).To(BeTrue())
+	})
+	It("Should drop the commands of the previous turn", func() {
+		commander.Command(context.Background())
+		Expect(commander.Published()).NotTo(BeEmpty())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		commander.SetMap(simulator.Generate(240, 160, 2, 1), 2)
+		commander.Command(ctx)
+
+		Expect(commander.Published()).To(BeEmpty())
+	})
+	It("Should not change the planned commands when publishing", func() {
+		plan := func(delay time.Duration) hlt.Command {
+			strategy := &slowStrategy{delay: delay}
+			commander := NewCommander()
+			commander.Strategy = strategy
+			// The enemy is expected to cross the way of the first pilot
+			commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(10, 50, 0, 2), newShip(23, 16, 1, 1)), 1)
+			commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(10, 50, 0, 2), newShip(20, 13, 1, 1)), 2)
+			commander.Command(context.Background())
+			return strategy.first
+		}
+
+		Expect(plan(0)).To(Equal(plan(100 * time.Millisecond)))
+	})
+})
@@ -0,0 +1,206 @@
+package control
+
+import (
+	"context"
+	"math"
+
+	"github.com/metalblueberry/halite-bot/pkg/assignment"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// clusterTurns is the travel time between planets of the same cluster
+const clusterTurns = 3
+
+// Route is the shortest path between the docking ranges of two planets
+type Route struct {
+	From, To int
+	// Distance is the length of the path following the Waypoints
+	Distance  float64
+	Waypoints []twoD.Positioner
+}
+
+type routeKey struct {
+	from, to int
+}
+
+// Prepare analyses the initial map before the first turn. The engine allows more time for the
+// initialization than for a turn, so everything that does not change during the game is computed here
+// and cached in the Commander. When the context is done it stops and returns the context error,
+// the analysis finished until then is kept
+func (c *Commander) Prepare(ctx context.Context, gameMap hlt.Map) error {
+	c.SetMap(gameMap, 0)
+
+	steps := []func(){
+		c.partitionTerritory,
+		c.planRoutes,
+		c.findClusters,
+		c.planOpening,
+	}
+	for _, step := range steps {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		step()
+	}
+	return ctx.Err()
+}
+
+// Route returns the cached path between two planets, see Prepare
+func (c *Commander) Route(from, to int) (Route, bool) {
+	route, exist := c.routes[routeKey{from, to}]
+	return route, exist
+}
+
+// travelDistance is the distance that the pilot flies to the surface of the target. Pilots in the docking range of
+// a planet going to another one follow its Route, that goes around the planets in between
+func (c *Commander) travelDistance(pilot *Pilot, target twoD.Positioner) float64 {
+	distance := math.Max(0, surfaceDistance(pilot, target))
+	planet, ok := target.(*PlanetStats)
+	if !ok {
+		return distance
+	}
+	for _, entity := range c.planetIndex.Nearest(pilot, 1, nil) {
+		from := entity.(*PlanetStats)
+		x, y, r := from.Circle()
+		if twoD.Distance(pilot, twoD.NewPosition(x, y)) > r+hlt.Constants.DockRadius+hlt.Constants.ShipRadius {
+			continue
+		}
+		if route, exist := c.Route(from.ID(), planet.ID()); exist {
+			return math.Max(distance, route.Distance)
+		}
+	}
+	return distance
+}
+
+// ownsCluster reports if we own any planet of the cluster of the planet, see findClusters
+func (c *Commander) ownsCluster(planet *PlanetStats) bool {
+	if planet.Cluster >= len(c.Clusters) {
+		return false
+	}
+	for _, id := range c.Clusters[planet.Cluster] {
+		if other := c.Planets[id]; other.Owned != 0 && other.Owner() == c.gameMap.MyID {
+			return true
+		}
+	}
+	return false
+}
+
+// planRoutes finds the paths between every pair of planets going around the other planets
+func (c *Commander) planRoutes() {
+	c.routes = make(map[routeKey]Route)
+	for i, from := range c.gameMap.Planets {
+		source := c.Planets[from.ID()]
+		sources := []navigation.Positioner{}
+		for _, tile := range c.dockRangeTiles(source) {
+			sources = append(sources, tile)
+		}
+		field := c.Grid.DistanceField(sources...)
+
+		for _, to := range c.gameMap.Planets[i+1:] {
+			tile, distance := c.dockRangeTile(field, c.Planets[to.ID()])
+			if tile == nil {
+				continue
+			}
+			path := c.Grid.Descend(field, tile)
+			waypoints := make([]twoD.Positioner, len(path))
+			reversed := make([]twoD.Positioner, len(path))
+			for j, step := range path {
+				waypoints[j] = twoD.NewPosition(step.Position())
+				reversed[len(path)-1-j] = waypoints[j]
+			}
+			c.routes[routeKey{to.ID(), from.ID()}] = Route{From: to.ID(), To: from.ID(), Distance: distance, Waypoints: waypoints}
+			c.routes[routeKey{from.ID(), to.ID()}] = Route{From: from.ID(), To: to.ID(), Distance: distance, Waypoints: reversed}
+		}
+	}
+}
+
+// findClusters groups the planets that are at most clusterTurns away from each other, directly or through other planets.
+// Clusters are sorted by the order of their first planet in the map
+func (c *Commander) findClusters() {
+	parent := make(map[int]int, len(c.gameMap.Planets))
+	var find func(id int) int
+	find = func(id int) int {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for _, planet := range c.gameMap.Planets {
+		parent[planet.ID()] = planet.ID()
+	}
+	for key, route := range c.routes {
+		if route.Distance <= clusterTurns*hlt.Constants.MaxSpeed {
+			parent[find(key.from)] = find(key.to)
+		}
+	}
+
+	c.Clusters = [][]int{}
+	clusterOf := make(map[int]int)
+	for _, planet := range c.gameMap.Planets {
+		root := find(planet.ID())
+		cluster, exist := clusterOf[root]
+		if !exist {
+			cluster = len(c.Clusters)
+			clusterOf[root] = cluster
+			c.Clusters = append(c.Clusters, []int{})
+		}
+		c.Clusters[cluster] = append(c.Clusters[cluster], planet.ID())
+		c.Planets[planet.ID()].Cluster = cluster
+	}
+}
+
+// planOpening assigns the initial ships to the planets that they can claim, filling the docking spots of the
+// closest safe planets first. The plan is followed during the opening turns while the planet can be settled
+func (c *Commander) planOpening() {
+	c.opening = make(map[int]*PlanetStats)
+	ships := c.Me().Ships
+	planets := c.GetPlanets()
+	if len(ships) == 0 || len(planets) == 0 {
+		return
+	}
+
+	// The initial ships start close together, so a single field from the whole fleet plus how much further each
+	// ship is than the closest one estimates the arrival of every ship
+	sources := make([]navigation.Positioner, len(ships))
+	cost := make([][]float64, len(ships))
+	for i, ship := range ships {
+		sources[i] = ship
+		cost[i] = make([]float64, len(planets))
+	}
+	field := c.Grid.DistanceField(sources...)
+	capacity := make([]int, len(planets))
+	for j, planet := range planets {
+		capacity[j] = int(planet.NumDockingSpots)
+		territory := float64(planet.Territory()) * float64(hlt.Constants.DockTurns)
+		tile, distance := c.dockRangeTile(field, planet)
+		if tile == nil || math.IsInf(distance, 1) {
+			for i := range ships {
+				cost[i][j] = unreachable + territory
+			}
+			continue
+		}
+		closest := math.Inf(1)
+		for _, ship := range ships {
+			closest = math.Min(closest, twoD.Distance(ship, tile))
+		}
+		for i, ship := range ships {
+			cost[i][j] = math.Ceil((distance+twoD.Distance(ship, tile)-closest)/hlt.Constants.MaxSpeed) + territory
+		}
+	}
+	for i, j := range assignment.SolveWithCapacity(cost, capacity) {
+		if j >= 0 {
+			c.opening[ships[i].ID()] = planets[j]
+		}
+	}
+}
+
+// OpeningPlan returns the planet planned for the ship during the opening, nil if it has none
+func (c *Commander) OpeningPlan(ship hlt.Ship) *PlanetStats {
+	planet, exist := c.opening[ship.ID()]
+	if !exist || c.currentTurn > openingTurns || c.Planets[planet.ID()] != planet || !c.canSettle(planet) {
+		return nil
+	}
+	return planet
+}
@@ -0,0 +1,124 @@
+package control_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/simulator"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+var _ = Describe("Prepare", func() {
+	var commander *Commander
+
+	BeforeEach(func() {
+		commander = NewCommander()
+	})
+
+	Context("With a generated map", func() {
+		var gameMap hlt.Map
+		BeforeEach(func() {
+			gameMap = simulator.Generate(240, 160, 2, 1)
+			Expect(commander.Prepare(context.Background(), gameMap)).To(Succeed())
+		})
+		It("Should find routes between every pair of planets", func() {
+			for _, from := range gameMap.Planets {
+				for _, to := range gameMap.Planets {
+					if from.ID() == to.ID() {
+						continue
+					}
+					route, exist := commander.Route(from.ID(), to.ID())
+					Expect(exist).To(BeTrue())
+					Expect(route.From).To(Equal(from.ID()))
+					Expect(route.To).To(Equal(to.ID()))
+					_, _, fromR := from.Circle()
+					_, _, toR := to.Circle()
+					dockRange := hlt.Constants.DockRadius + hlt.Constants.ShipRadius
+					Expect(route.Distance).To(BeNumerically(">=", twoD.Distance(from, to)-fromR-toR-2*dockRange-2))
+					Expect(route.Waypoints).NotTo(BeEmpty())
+				}
+			}
+		})
+		It("Should return the waypoints of both directions in opposite order", func() {
+			there, _ := commander.Route(gameMap.Planets[0].ID(), gameMap.Planets[1].ID())
+			back, _ := commander.Route(gameMap.Planets[1].ID(), gameMap.Planets[0].ID())
+
+			Expect(there.Distance).To(Equal(back.Distance))
+			Expect(there.Waypoints[0]).To(Equal(back.Waypoints[len(back.Waypoints)-1]))
+		})
+		It("Should put every planet in one cluster", func() {
+			count := 0
+			for i, cluster := range commander.Clusters {
+				for _, id := range cluster {
+					Expect(commander.Planets[id].Cluster).To(Equal(i))
+					count++
+				}
+			}
+			Expect(count).To(Equal(len(gameMap.Planets)))
+		})
+		It("Should plan the opening of every ship to a planet that is not lost", func() {
+			commander.SetMap(gameMap, 1)
+			for _, ship := range commander.Me().Ships {
+				planet := commander.OpeningPlan(ship)
+				Expect(planet).NotTo(BeNil())
+				Expect(planet.Territory()).NotTo(Equal(LostTerritory))
+			}
+		})
+	})
+	It("Should stop when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := commander.Prepare(ctx, simulator.Generate(240, 160, 2, 1))
+		Expect(err).To(Equal(context.Canceled))
+		Expect(commander.Clusters).To(BeNil())
+	})
+	It("Should cost the route around the planets in between", func() {
+		gameMap := newMap(newShip(27, 50, 0, 0))
+		gameMap.Planets = []hlt.Planet{
+			{Entity: hlt.NewEntity(20, 50, 3, 765, 0, 0), NumDockingSpots: 1},
+			{Entity: hlt.NewEntity(50, 50, 10, 2550, 0, 1), NumDockingSpots: 3},
+			{Entity: hlt.NewEntity(80, 50, 3, 765, 0, 2), NumDockingSpots: 1},
+		}
+		Expect(commander.Prepare(context.Background(), gameMap)).To(Succeed())
+		commander.SetMap(gameMap, 1)
+		target := commander.Planets[2]
+		route, _ := commander.Route(0, 2)
+
+		cost := commander.Cost(commander.Pilots[0], Target{Kind: Settle, Position: target})
+
+		Expect(route.Distance).To(BeNumerically(">", twoD.Distance(commander.Pilots[0], target)-3))
+		Expect(cost).To(BeNumerically("~", route.Distance/hlt.Constants.MaxSpeed, 1e-9))
+	})
+	It("Should prefer the planets of the clusters we own", func() {
+		gameMap := newMap(newShip(30, 50, 0, 0))
+		gameMap.Planets = []hlt.Planet{
+			{Entity: hlt.NewEntity(20, 20, 3, 765, 0, 0), NumDockingSpots: 1, NumDockedShips: 1, Owned: 1},
+			{Entity: hlt.NewEntity(30, 20, 3, 765, 0, 1), NumDockingSpots: 1},
+			{Entity: hlt.NewEntity(30, 80, 3, 765, 0, 2), NumDockingSpots: 1},
+		}
+		Expect(commander.Prepare(context.Background(), gameMap)).To(Succeed())
+		commander.SetMap(gameMap, 1)
+		pilot := commander.Pilots[0]
+
+		near := commander.Cost(pilot, Target{Kind: Settle, Position: commander.Planets[1]})
+		far := commander.Cost(pilot, Target{Kind: Settle, Position: commander.Planets[2]})
+
+		Expect(commander.Planets[0].Cluster).To(Equal(commander.Planets[1].Cluster))
+		Expect(near).To(BeNumerically("<", far))
+	})
+	It("Should group close planets in the same cluster", func() {
+		gameMap := newMap(newShip(10, 10, 0, 0))
+		gameMap.Planets = append(gameMap.Planets,
+			hlt.Planet{Entity: hlt.NewEntity(80, 60, 5, 1275, 0, 1), NumDockingSpots: 2},
+			hlt.Planet{Entity: hlt.NewEntity(20, 80, 5, 1275, 0, 2), NumDockingSpots: 2},
+		)
+		Expect(commander.Prepare(context.Background(), gameMap)).To(Succeed())
+
+		Expect(commander.Clusters).To(Equal([][]int{{0, 1}, {2}}))
+	})
+})
@@ -0,0 +1,223 @@
+package control
+
+import (
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// Role is the job a pilot commits to until it is done or a much better one shows up
+type Role int
+
+const (
+	// NoRole is the role of pilots without commitment
+	NoRole Role = iota
+	// Settler pilots go to planets to dock
+	Settler
+	// Attacker pilots chase enemy ships in open space
+	Attacker
+	// Defender pilots protect our planets from the enemies around them
+	Defender
+	// Harasser pilots attack enemy docked ships
+	Harasser
+	// Escort pilots follow one of our ships
+	Escort
+)
+
+func (r Role) String() string {
+	return [...]string{"none", "settler", "attacker", "defender", "harasser", "escort"}[r]
+}
+
+// roleWeights are the costs of the grid layers for the roles that do not path with the navigation.DefaultWeights.
+// Attackers and defenders fly to the enemies, so their fire is not worth a detour
+var roleWeights = map[Role]navigation.Weights{
+	Attacker: navigation.DefaultWeights.With(navigation.EnemyFireLayer, 0),
+	Defender: navigation.DefaultWeights.With(navigation.EnemyFireLayer, 0),
+}
+
+// PilotState is the step of the pilot lifecycle, docking states follow the engine docking status
+type PilotState int
+
+const (
+	// Idle pilots are undocked and have no target
+	Idle PilotState = iota
+	// Travelling pilots fly to a planet or a position
+	Travelling
+	// Docking pilots are docking to a planet
+	Docking
+	// Docked pilots are producing ships
+	Docked
+	// Undocking pilots are leaving a planet
+	Undocking
+	// Fighting pilots chase an enemy ship
+	Fighting
+)
+
+func (s PilotState) String() string {
+	return [...]string{"idle", "travelling", "docking", "docked", "undocking", "fighting"}[s]
+}
+
+// transitions lists the valid changes of state. Docking states can also be forced by the engine, see syncState
+var transitions = map[PilotState][]PilotState{
+	Idle:       {Travelling, Fighting, Docking},
+	Travelling: {Idle, Travelling, Fighting, Docking},
+	Docking:    {Docked, Idle},
+	Docked:     {Undocking, Idle},
+	Undocking:  {Idle, Travelling, Fighting},
+	Fighting:   {Idle, Travelling, Fighting, Docking},
+}
+
+const (
+	// minCommitTurns is the number of turns that a pilot keeps a valid target before considering others
+	minCommitTurns = 3
+	// switchRatio is how much closer a new target must be to replace the current one
+	switchRatio = 0.6
+)
+
+// Transition changes the state of the pilot if it is allowed from the current one
+func (pilot *Pilot) Transition(to PilotState) bool {
+	for _, allowed := range transitions[pilot.State] {
+		if allowed == to {
+			pilot.State = to
+			return true
+		}
+	}
+	return false
+}
+
+// syncState follows the docking status reported by the engine. When a pilot leaves a
+// planet, or it is released because the planet is destroyed, it drops its commitment
+func (pilot *Pilot) syncState() {
+	switch pilot.DockingStatus {
+	case hlt.DOCKING:
+		pilot.State = Docking
+	case hlt.DOCKED:
+		pilot.State = Docked
+	case hlt.UNDOCKING:
+		pilot.State = Undocking
+	default:
+		if pilot.State == Docking || pilot.State == Docked || pilot.State == Undocking {
+			pilot.State = Idle
+			pilot.Release()
+		}
+	}
+}
+
+// Commit assigns the target to the pilot from the given turn
+func (pilot *Pilot) Commit(role Role, target twoD.Positioner, turn int) {
+	if !sameTarget(pilot.target, target) {
+		pilot.committedTurn = turn
+	}
+	pilot.Role = role
+	pilot.target = target
+	if role == Attacker || role == Harasser {
+		pilot.Transition(Fighting)
+	} else {
+		pilot.Transition(Travelling)
+	}
+}
+
+// Release drops the commitment of the pilot
+func (pilot *Pilot) Release() {
+	pilot.Role = NoRole
+	pilot.target = nil
+	if pilot.State == Travelling || pilot.State == Fighting {
+		pilot.Transition(Idle)
+	}
+}
+
+// Target returns the target the pilot is committed to, nil if it has none
+func (pilot *Pilot) Target() twoD.Positioner {
+	return pilot.target
+}
+
+// sameTarget compares targets by identity, ships are copied every turn so they are compared by ID
+func sameTarget(a, b twoD.Positioner) bool {
+	shipA, okA := a.(hlt.Ship)
+	shipB, okB := b.(hlt.Ship)
+	if okA || okB {
+		return okA && okB && shipA.ID() == shipB.ID()
+	}
+	return a == b
+}
+
+// roleFor deduces the role of a pilot that goes for the target
+func (c *Commander) roleFor(target twoD.Positioner) Role {
+	switch target := target.(type) {
+	case *PlanetStats:
+		return Settler
+	case hlt.Ship:
+		if target.Owner() == c.gameMap.MyID {
+			return Escort
+		}
+		if target.DockingStatus != hlt.UNDOCKED {
+			return Harasser
+		}
+		for _, planet := range c.planetIndex.Within(target, interceptRange()) {
+			if planet.(*PlanetStats).Owned != 0 && planet.Owner() == c.gameMap.MyID {
+				return Defender
+			}
+		}
+		return Attacker
+	default:
+		return Defender
+	}
+}
+
+// refreshCommitments updates the targets of the pilots with the state of the new turn
+// and releases the pilots whose target is gone or can not be reached anymore
+func (c *Commander) refreshCommitments() {
+	for _, pilot := range c.Pilots {
+		switch target := pilot.target.(type) {
+		case nil:
+		case *PlanetStats:
+			if c.Planets[target.ID()] != target || !c.canSettle(target) {
+				pilot.Release()
+			}
+		case hlt.Ship:
+			ship, exist := c.gameMap.Ships[target.ID()]
+			if !exist {
+				pilot.Release()
+				continue
+			}
+			pilot.target = ship
+		default:
+			if surfaceDistance(pilot, target) < hlt.Constants.ShipRadius {
+				pilot.Release()
+			}
+		}
+	}
+}
+
+// canSettle reports if we can dock more ships to the planet
+func (c *Commander) canSettle(planet *PlanetStats) bool {
+	return (planet.Owned == 0 || planet.Owner() == c.gameMap.MyID) && planet.NumDockedShips < planet.NumDockingSpots
+}
+
+// commit applies the hysteresis rules to the target chosen by the strategy. The pilot keeps its
+// current target for a few turns and after that it only switches to targets that are much closer,
+// or it is released when the strategy has no target for it
+func (c *Commander) commit(pilot *Pilot, candidate twoD.Positioner) twoD.Positioner {
+	current := pilot.target
+	switch {
+	case candidate == nil && current == nil:
+		return nil
+	case current == nil, sameTarget(current, candidate):
+		pilot.Commit(c.roleFor(candidate), candidate, c.currentTurn)
+	case c.currentTurn-pilot.committedTurn < minCommitTurns:
+	case candidate == nil:
+		pilot.Release()
+	case surfaceDistance(pilot, candidate) < switchRatio*surfaceDistance(pilot, current):
+		pilot.Commit(c.roleFor(candidate), candidate, c.currentTurn)
+	}
+	return pilot.target
+}
+
+func surfaceDistance(from, to twoD.Positioner) float64 {
+	distance := twoD.Distance(from, to)
+	if circle, ok := to.(twoD.Circler); ok {
+		_, _, r := circle.Circle()
+		distance -= r
+	}
+	return distance
+}
@@ -0,0 +1,129 @@
+package control_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// fixedStrategy sends every pilot to the same target
+type fixedStrategy struct {
+	target twoD.Positioner
+}
+
+func (s *fixedStrategy) Target(c *Commander, pilot *Pilot) twoD.Positioner {
+	return s.target
+}
+
+func (s *fixedStrategy) Dock(c *Commander, pilot *Pilot, planet *PlanetStats) bool {
+	return true
+}
+
+func (s *fixedStrategy) Undock(c *Commander, pilot *Pilot) bool {
+	return false
+}
+
+var _ = Describe("Roles", func() {
+	var (
+		commander *Commander
+		strategy  *fixedStrategy
+	)
+
+	BeforeEach(func() {
+		commander = NewCommander()
+		strategy = &fixedStrategy{}
+		commander.Strategy = strategy
+	})
+
+	play := func(turn int, ships ...hlt.Ship) {
+		commander.SetMap(newMap(ships...), turn)
+		commander.Command(context.Background())
+	}
+
+	It("Should only allow valid transitions", func() {
+		pilot := NewPilot()
+		Expect(pilot.State).To(Equal(Idle))
+		Expect(pilot.Transition(Docked)).To(BeFalse())
+		Expect(pilot.Transition(Travelling)).To(BeTrue())
+		Expect(pilot.Transition(Docking)).To(BeTrue())
+		Expect(pilot.Transition(Fighting)).To(BeFalse())
+		Expect(pilot.Transition(Docked)).To(BeTrue())
+		Expect(pilot.Transition(Undocking)).To(BeTrue())
+		Expect(pilot.Transition(Fighting)).To(BeTrue())
+		Expect(pilot.State.String()).To(Equal("fighting"))
+	})
+	It("Should keep the target for a few turns", func() {
+		enemy := newShip(40, 10, 1, 1)
+		strategy.target = twoD.NewPosition(30, 10)
+		play(1, newShip(10, 10, 0, 0), enemy)
+		pilot := commander.Pilots[0]
+		Expect(pilot.Role).To(Equal(Defender))
+		Expect(pilot.State).To(Equal(Travelling))
+
+		strategy.target = enemy
+		play(2, newShip(10, 10, 0, 0), enemy)
+		Expect(pilot.Target()).To(Equal(twoD.NewPosition(30, 10)))
+
+		// After the commitment the new target must be much closer
+		play(4, newShip(10, 10, 0, 0), enemy)
+		Expect(pilot.Target()).To(Equal(twoD.NewPosition(30, 10)))
+
+		strategy.target = newShip(12, 10, 1, 1)
+		play(5, newShip(10, 10, 0, 0), newShip(12, 10, 1, 1))
+		Expect(pilot.Role).To(Equal(Attacker))
+		Expect(pilot.State).To(Equal(Fighting))
+	})
+	It("Should release the pilot when the target is destroyed", func() {
+		enemy := newShip(40, 10, 1, 1)
+		strategy.target = enemy
+		play(1, newShip(10, 10, 0, 0), enemy)
+		pilot := commander.Pilots[0]
+		Expect(pilot.Role).To(Equal(Attacker))
+
+		strategy.target = nil
+		play(2, newShip(10, 10, 0, 0))
+		Expect(pilot.Target()).To(BeNil())
+		Expect(pilot.Role).To(Equal(NoRole))
+		Expect(pilot.State).To(Equal(Idle))
+	})
+	It("Should release the pilot when the strategy has no target after the commitment", func() {
+		strategy.target = twoD.NewPosition(30, 10)
+		play(1, newShip(10, 10, 0, 0))
+		pilot := commander.Pilots[0]
+
+		strategy.target = nil
+		play(2, newShip(10, 10, 0, 0))
+		Expect(pilot.Target()).To(Equal(twoD.NewPosition(30, 10)))
+
+		play(4, newShip(10, 10, 0, 0))
+		Expect(pilot.Target()).To(BeNil())
+		Expect(pilot.Role).To(Equal(NoRole))
+		Expect(pilot.State).To(Equal(Idle))
+	})
+	It("Should follow the docking status of the ship", func() {
+		// newMap has a planet with 2 spots at 80,80
+		settler := newShip(73, 80, 0, 0)
+		play(1, settler)
+		strategy.target = commander.Planets[0]
+		play(2, settler)
+		pilot := commander.Pilots[0]
+		Expect(pilot.Role).To(Equal(Settler))
+		Expect(pilot.State).To(Equal(Docking))
+		Expect(pilot.Command).To(Equal(hlt.DockCommand{ShipID: 0, PlanetID: 0}))
+
+		settler.DockingStatus = hlt.DOCKED
+		play(3, settler)
+		Expect(pilot.State).To(Equal(Docked))
+		Expect(pilot.Role).To(Equal(Settler))
+
+		settler.DockingStatus = hlt.UNDOCKED
+		play(4, settler)
+		Expect(pilot.Role).To(Equal(Settler))
+		Expect(pilot.State).To(Equal(Docking))
+	})
+})
@@ -3,6 +3,7 @@ package control
 import (
 	//log "github.com/sirupsen/logrus"
 
+	"context"
 	"errors"
 	"strconv"
 
@@ -13,10 +14,53 @@ import (
 	"github.com/metalblueberry/halite-bot/pkg/twoD"
 )
 
+func init() {
+	RegisterStrategy(DefaultStrategy, func() Strategy { return &assignmentStrategy{} })
+	RegisterStrategy("greedy", func() Strategy { return greedyStrategy{} })
+	RegisterStrategy("raid", func() Strategy { return raidStrategy{} })
+}
+
+// greedyStrategy settles the most valuable planets and defends them from the closest enemies, every pilot picks
+// its target with FindTarget
+type greedyStrategy struct{}
+
+func (greedyStrategy) Target(c *Commander, pilot *Pilot) twoD.Positioner {
+	return c.FindTarget(pilot)
+}
+
+func (greedyStrategy) Dock(c *Commander, pilot *Pilot, planet *PlanetStats) bool {
+	return true
+}
+
+func (greedyStrategy) Undock(c *Commander, pilot *Pilot) bool {
+	return false
+}
+
+// raidStrategy attacks the closest docked enemy, they can not defend themselves, and otherwise plays like the greedy
+// strategy
+type raidStrategy struct {
+	greedyStrategy
+}
+
+func (raidStrategy) Target(c *Commander, pilot *Pilot) twoD.Positioner {
+	docked := c.shipIndex.Nearest(pilot, 1, func(entity hlt.Entitier) bool {
+		ship := entity.(*hlt.Ship)
+		return ship.Owner() != c.gameMap.MyID && ship.DockingStatus != hlt.UNDOCKED
+	})
+	if len(docked) > 0 {
+		return *docked[0].(*hlt.Ship)
+	}
+	return c.FindTarget(pilot)
+}
+
 func (c *Commander) FindTarget(pilot *Pilot) twoD.Positioner {
 
+	if planet := c.OpeningPlan(pilot.Ship); planet != nil {
+		return planet
+	}
+
 	// if Pilot can fight, look for trouble
-	if pilot.Health() > 255/4 && pilot.ClosestPlanet.Owner() == c.gameMap.MyID {
+	if pilot.Health() > hlt.Constants.MaxShipHealth/4 && pilot.ClosestPlanet.Owner() == c.gameMap.MyID {
 		for _, inOrbitShip := range pilot.ClosestPlanet.InOrbitShips {
 			if inOrbitShip.Owner() != c.gameMap.MyID {
 				return inOrbitShip
@@ -29,23 +73,30 @@ func (c *Commander) FindTarget(pilot *Pilot) twoD.Positioner {
 	for _, planet := range planets {
 		if (planet.Owned == 0 || planet.Owner() == c.gameMap.MyID) && planet.NumDockedShips < planet.NumDockingSpots {
 
-			// Select enemy ship if is close to the planet
-			for _, ship := range planet.InOrbitShips {
+			// Select enemy ship if is close to the planet and we do not control the area
+			_, _, r := planet.Circle()
+			if !c.Contested(planet) {
+				return planet
+			}
+			for _, entity := range c.shipIndex.Within(planet, r+3*hlt.Constants.DockRadius) {
+				ship := entity.(*hlt.Ship)
 				if ship.Owner() == c.gameMap.MyID {
 					continue
 				}
-				_, _, r := planet.Circle()
-				if twoD.Distance(ship, planet)-r < 3*hlt.Constants["DOCK_RADIUS"].(float64) {
-					return ship
+				if twoD.Distance(ship, planet)-r < 3*hlt.Constants.DockRadius {
+					return *ship
 				}
 			}
 
 			return planet
 		}
 		if planet.Owner() != c.gameMap.MyID {
-			//TODO: find nearest ship
-			for _, enemy := range planet.DockedShipIDs {
-				return c.gameMap.Ships[enemy]
+			docked := c.shipIndex.Nearest(pilot, 1, func(entity hlt.Entitier) bool {
+				ship := entity.(*hlt.Ship)
+				return ship.DockingStatus != hlt.UNDOCKED && ship.PlanetID == planet.ID() && ship.Owner() == planet.Owner()
+			})
+			if len(docked) > 0 {
+				return *docked[0].(*hlt.Ship)
 			}
 		}
 	}
@@ -53,7 +104,24 @@ func (c *Commander) FindTarget(pilot *Pilot) twoD.Positioner {
 	return nil
 }
 
-func (gameMap *Commander) CalculatePath(pilot *Pilot, target twoD.Positioner) ([]*navigation.Tile, error) {
+// CalculatePath finds the path to the target, the search stops when the context is done.
+// The targets shared by several pilots use their flow field instead of a search and distant targets are planned
+// on the hierarchy, refining only the next part of the path. The tiles are weighted for the role of the pilot
+func (gameMap *Commander) CalculatePath(ctx context.Context, pilot *Pilot, target twoD.Positioner) ([]*navigation.Tile, error) {
+	weighter := gameMap.weighters[pilot.Role]
+
+	// Shared targets read the path from their flow field, enough steps to cover the reservation window.
+	// The fields are built with the default weights and the enemy fire of an earlier turn, and they ignore the
+	// reservations, so paths where the fire changed or that cross reservations are searched
+	if field := gameMap.FlowField(target); field != nil && weighter == nil {
+		steps := int(reservationWindow*hlt.Constants.MaxSpeed*gameMap.Grid.Scale) + 1
+		path := field.Path(gameMap.Grid.GetTile(pilot.Position()), steps)
+		if len(path) > 1 && !field.Stale(gameMap.Grid, path) && !gameMap.Grid.Reservations.Reserved(path) {
+			halitedebug.Line(twoD.NewLine(path[0], path[len(path)-1]), "flowField")
+			return path, nil
+		}
+	}
+
 	// If target has a radius, calculate a near position with a margin
 	switch targetType := target.(type) {
 	case twoD.Circler:
@@ -63,7 +131,12 @@ func (gameMap *Commander) CalculatePath(pilot *Pilot, target twoD.Positioner) ([
 	//log.Printf("Planet %v, Point %v", planet.Entity, target)
 	from := gameMap.Grid.GetTile(pilot.Position())
 	to := gameMap.Grid.GetTile(target.Position())
-	_, _, found, path := gameMap.Grid.Path(from, to, 300)
+	search := gameMap.Grid.PathWeighted
+	if from.DistanceTo(to)*gameMap.Grid.Scale > hierarchyDistance {
+		search = gameMap.hierarchy.PathWeighted
+	}
+	_, _, found, path, stats := search(ctx, weighter, from, to, 300)
+	gameMap.recordSearch(stats)
 
 	if !found {
 		halitedebug.Line(twoD.NewLine(from, to), "notFound")
@@ -84,19 +157,38 @@ func (gameMap *Commander) CalculatePath(pilot *Pilot, target twoD.Positioner) ([
 	return path, nil
 }
 
-// GetPathForTurn returns the tile at which you can move in straight line at the desired speed
-func GetPathForTurn(gameMap hlt.Map, pilot *Pilot, path []*navigation.Tile, speed float64) []*navigation.Tile {
-	for i, tile := range path[0:] {
-		totalDistance := tile.DistanceTo(pilot)
-		if totalDistance > speed {
-			return path[0:i]
+// GetWaypointForTurn returns the furthest point that the pilot can reach in straight line within MAX_SPEED
+// following the waypoints of an any-angle path, and the waypoints that follow it. Waypoints closer than MAX_SPEED
+// are skipped when the line to the next one is clear, but the pilot stops at a repeated waypoint, where the path waits
+// for a reservation to be released. It is false when the line to the first waypoint hits an obstacle or the path
+// waits where the pilot is
+func GetWaypointForTurn(gameMap hlt.Map, pilot *Pilot, waypoints []*navigation.Tile) (destination twoD.Positioner, rest []*navigation.Tile, ok bool) {
+	for i, waypoint := range waypoints {
+		if i > 0 && waypoint == waypoints[i-1] {
+			break
 		}
-		blocked, collider := gameMap.ObstaclesBetween(pilot, tile, pilot.ID())
-		halitedebug.Line(twoD.NewLine(pilot, tile), "direction", "block"+strconv.FormatBool(blocked))
+		distance := waypoint.DistanceTo(pilot)
+		if distance < 1 {
+			continue
+		}
+		var point twoD.Positioner = waypoint
+		next := i + 1
+		if distance > hlt.Constants.MaxSpeed {
+			x, y := pilot.Position()
+			ux, uy := twoD.UnitVector(pilot, waypoint)
+			point = twoD.NewPosition(x+ux*hlt.Constants.MaxSpeed, y+uy*hlt.Constants.MaxSpeed)
+			next = i
+		}
+		blocked, collider := gameMap.ObstaclesBetween(pilot, point, pilot.ID())
+		halitedebug.Line(twoD.NewLine(pilot, point), "direction", "block"+strconv.FormatBool(blocked))
 		if blocked {
 			halitedebug.Circle(collider, "collider")
-			return path[0:i]
+			break
+		}
+		destination, rest = point, waypoints[next:]
+		if distance > hlt.Constants.MaxSpeed {
+			break
 		}
 	}
-	return path
+	return destination, rest, destination != nil
 }
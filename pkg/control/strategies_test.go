@@ -0,0 +1,124 @@
+package control_test
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+var _ = Describe("Waypoints", func() {
+	var commander *Commander
+
+	BeforeEach(func() {
+		commander = NewCommander()
+	})
+
+	It("Should fly at full speed towards the first waypoint out of reach", func() {
+		gameMap := newMap(newShip(10, 10, 0, 0))
+		commander.SetMap(gameMap, 1)
+		grid := commander.Grid
+		waypoints := []*navigation.Tile{grid.GetTile(10, 10), grid.GetTile(12, 11), grid.GetTile(40, 20)}
+
+		destination, rest, ok := GetWaypointForTurn(gameMap, commander.Pilots[0], waypoints)
+
+		Expect(ok).To(BeTrue())
+		Expect(twoD.Distance(commander.Pilots[0], destination)).To(BeNumerically("~", hlt.Constants.MaxSpeed, 1e-9))
+		Expect(twoD.DistancePointToLine(commander.Pilots[0], grid.GetTile(40, 20), destination)).To(BeNumerically("~", 0, 1e-9))
+		Expect(rest).To(Equal(waypoints[2:]))
+	})
+	It("Should stop at the last waypoint", func() {
+		gameMap := newMap(newShip(10, 10, 0, 0))
+		commander.SetMap(gameMap, 1)
+		grid := commander.Grid
+
+		destination, rest, ok := GetWaypointForTurn(gameMap, commander.Pilots[0], []*navigation.Tile{grid.GetTile(10, 10), grid.GetTile(13, 14)})
+
+		Expect(ok).To(BeTrue())
+		Expect(destination).To(Equal(grid.GetTile(13, 14)))
+		Expect(rest).To(BeEmpty())
+	})
+	It("Should stop where the path waits", func() {
+		gameMap := newMap(newShip(10, 10, 0, 0))
+		commander.SetMap(gameMap, 1)
+		grid := commander.Grid
+		waypoints := []*navigation.Tile{grid.GetTile(10, 10), grid.GetTile(12, 11), grid.GetTile(12, 11), grid.GetTile(40, 20)}
+
+		destination, rest, ok := GetWaypointForTurn(gameMap, commander.Pilots[0], waypoints)
+
+		Expect(ok).To(BeTrue())
+		Expect(destination).To(Equal(grid.GetTile(12, 11)))
+		Expect(rest).To(Equal(waypoints[2:]))
+
+		_, _, ok = GetWaypointForTurn(gameMap, commander.Pilots[0], []*navigation.Tile{grid.GetTile(10, 10), grid.GetTile(10, 10), grid.GetTile(40, 20)})
+		Expect(ok).To(BeFalse())
+	})
+	It("Should not move when the line is blocked", func() {
+		gameMap := newMap(newShip(10, 10, 0, 0), newShip(13, 10, 1, 1))
+		commander.SetMap(gameMap, 1)
+		grid := commander.Grid
+
+		_, _, ok := GetWaypointForTurn(gameMap, commander.Pilots[0], []*navigation.Tile{grid.GetTile(10, 10), grid.GetTile(30, 10)})
+
+		Expect(ok).To(BeFalse())
+	})
+	It("Should fly in straight line towards a distant planet", func() {
+		gameMap := newMap(newShip(10, 12, 0, 0))
+		commander.SetMap(gameMap, 1)
+		grid := commander.Grid
+
+		path, err := commander.CalculatePath(context.Background(), commander.Pilots[0], gameMap.Planets[0])
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(path)).To(BeNumerically("<=", 3))
+		Expect(path[0]).To(Equal(grid.GetTile(10, 12)))
+	})
+	It("Should plan the whole path to distant targets", func() {
+		gameMap := newMap(newShip(10, 10, 0, 0))
+		commander.SetMap(gameMap, 1)
+		planet := gameMap.Planets[0]
+
+		path, err := commander.CalculatePath(context.Background(), commander.Pilots[0], planet)
+
+		Expect(err).NotTo(HaveOccurred())
+		_, _, radius := planet.Circle()
+		Expect(path[len(path)-1].DistanceTo(planet)).To(BeNumerically("<", radius+3))
+	})
+	It("Should weight the enemy fire for the role of the pilot", func() {
+		gameMap := newMap(newShip(10, 50, 0, 0), newShip(30, 58, 1, 1))
+		commander.SetMap(gameMap, 1)
+		target := twoD.NewPosition(50, 50)
+
+		path, err := commander.CalculatePath(context.Background(), commander.Pilots[0], target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(ContainElement(WithTransform(func(tile *navigation.Tile) float64 { return tile.Y }, BeNumerically("<", 50))))
+
+		commander.Pilots[0].Role = Attacker
+		path, err = commander.CalculatePath(context.Background(), commander.Pilots[0], target)
+		Expect(err).NotTo(HaveOccurred())
+		// The first step leaves the tiles of the own ship
+		for _, tile := range path {
+			Expect(tile.Y).To(BeNumerically("~", 50, 1))
+		}
+	})
+	It("Should give map positions to the pilots on scaled grids", func() {
+		commander.GridScale = 2
+		gameMap := newMap(newShip(10.2, 12.4, 0, 0))
+		commander.SetMap(gameMap, 1)
+		planet := gameMap.Planets[0]
+
+		path, err := commander.CalculatePath(context.Background(), commander.Pilots[0], planet)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commander.Grid.Width).To(Equal(2 * gameMap.Width))
+		Expect(path[0].DistanceTo(commander.Pilots[0])).To(BeNumerically("<=", 0.25*math.Sqrt2))
+		_, _, radius := planet.Circle()
+		Expect(path[len(path)-1].DistanceTo(planet)).To(BeNumerically("<", radius+3))
+	})
+})
@@ -0,0 +1,54 @@
+package control
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// DefaultStrategy is the name of the strategy used when none is selected
+const DefaultStrategy = "default"
+
+// Strategy takes the decisions of the pilots, the Commander handles the navigation and the collisions
+type Strategy interface {
+	// Target returns where the pilot should go, a nil target keeps the pilot still.
+	// When the target is a planet the pilot docks to it if Dock agrees
+	Target(c *Commander, pilot *Pilot) twoD.Positioner
+	// Dock is called when the pilot is able to dock to the planet it is targeting
+	Dock(c *Commander, pilot *Pilot, planet *PlanetStats) bool
+	// Undock is called for every docked pilot before looking for targets
+	Undock(c *Commander, pilot *Pilot) bool
+}
+
+// StrategyFactory creates a new instance of a strategy, strategies can keep state during a game
+type StrategyFactory func() Strategy
+
+var strategies = map[string]StrategyFactory{}
+
+// RegisterStrategy makes a strategy available by name, registering the same name twice panics
+func RegisterStrategy(name string, factory StrategyFactory) {
+	if _, exist := strategies[name]; exist {
+		panic(fmt.Sprintf("strategy %q already registered", name))
+	}
+	strategies[name] = factory
+}
+
+// NewStrategy creates the strategy registered with the given name
+func NewStrategy(name string) (Strategy, error) {
+	factory, exist := strategies[name]
+	if !exist {
+		return nil, fmt.Errorf("unknown strategy %q, available strategies are %v", name, StrategyNames())
+	}
+	return factory(), nil
+}
+
+// StrategyNames returns the registered strategies sorted by name
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,70 @@
+package control_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/simulator"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// undockStrategy never moves and releases every docked pilot
+type undockStrategy struct{}
+
+func (undockStrategy) Target(c *Commander, pilot *Pilot) twoD.Positioner {
+	return nil
+}
+
+func (undockStrategy) Dock(c *Commander, pilot *Pilot, planet *PlanetStats) bool {
+	return false
+}
+
+func (undockStrategy) Undock(c *Commander, pilot *Pilot) bool {
+	return true
+}
+
+var _ = Describe("Strategy", func() {
+	It("Should have the default strategy registered", func() {
+		Expect(StrategyNames()).To(ContainElement(DefaultStrategy))
+		strategy, err := NewStrategy(DefaultStrategy)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(NewCommander().Strategy).To(Equal(strategy))
+	})
+	It("Should fail with unknown strategies", func() {
+		_, err := NewStrategy("unknown")
+		Expect(err).To(HaveOccurred())
+	})
+	It("Should not allow registering the same name twice", func() {
+		Expect(func() {
+			RegisterStrategy(DefaultStrategy, func() Strategy { return undockStrategy{} })
+		}).To(Panic())
+	})
+	It("Should delegate the decisions to the strategy", func() {
+		docked := newShip(10, 10, 0, 0)
+		docked.DockingStatus = hlt.DOCKED
+		commander := NewCommander()
+		commander.Strategy = undockStrategy{}
+		commander.SetMap(newMap(docked, newShip(20, 20, 0, 1)), 1)
+
+		commander.Command(context.Background())
+		Expect(commander.CommandQueue()).To(Equal([]hlt.Command{hlt.UndockCommand{ShipID: 0}}))
+	})
+	It("Should win with every registered strategy", func() {
+		for _, name := range StrategyNames() {
+			strategy, err := NewStrategy(name)
+			Expect(err).ToNot(HaveOccurred())
+			commander := NewCommander()
+			commander.Strategy = strategy
+
+			sim := simulator.New(simulator.Generate(240, 160, 2, 1))
+			idle := func(gameMap hlt.Map) []string { return nil }
+			winner, err := sim.Play(commanderBot(commander), idle)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(winner).To(Equal(0), name)
+		}
+	})
+})
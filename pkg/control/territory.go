@@ -0,0 +1,129 @@
+package control
+
+import (
+	"math"
+	"sort"
+
+	halitedebug "github.com/metalblueberry/Halite-debug/pkg/client"
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+// Territory tells who can claim a planet first at the start of the game
+type Territory int
+
+const (
+	// SafeTerritory planets can be docked before any enemy reaches them
+	SafeTerritory Territory = iota
+	// ContestedTerritory planets are reached by us and an enemy at about the same time
+	ContestedTerritory
+	// LostTerritory planets are docked by an enemy before we can reach them
+	LostTerritory
+)
+
+func (t Territory) String() string {
+	return [...]string{"safe", "contested", "lost"}[t]
+}
+
+const (
+	// unreachable is the arrival turn of players that can not reach a planet
+	unreachable = math.MaxInt32
+	// openingTurns is the number of turns that the expansion follows the territory partition
+	openingTurns = 30
+)
+
+// Territory classifies the planet with the StaticValue computed by partitionTerritory. A player
+// that arrives DOCK_TURNS earlier has finished docking when the other one arrives
+func (stats *PlanetStats) Territory() Territory {
+	margin := float64(hlt.Constants.DockTurns)
+	switch {
+	case stats.StaticValue >= margin:
+		return SafeTerritory
+	case stats.StaticValue <= -margin:
+		return LostTerritory
+	default:
+		return ContestedTerritory
+	}
+}
+
+// TerritoryMap returns the territory of every planet by ID
+func (c *Commander) TerritoryMap() map[int]Territory {
+	territory := make(map[int]Territory, len(c.Planets))
+	for id, stats := range c.Planets {
+		territory[id] = stats.Territory()
+	}
+	return territory
+}
+
+// partitionTerritory computes for every planet the earliest turn each player can reach its docking range,
+// following paths around the planets. StaticValue is the advantage in turns over the fastest enemy
+func (c *Commander) partitionTerritory() {
+	fields := make([][]float64, len(c.gameMap.Players))
+	for i, player := range c.gameMap.Players {
+		sources := make([]navigation.Positioner, 0, len(player.Ships))
+		for _, ship := range player.Ships {
+			if ship.DockingStatus == hlt.UNDOCKED {
+				sources = append(sources, ship)
+			}
+		}
+		fields[i] = c.Grid.DistanceField(sources...)
+	}
+
+	for _, planet := range c.gameMap.Planets {
+		stats := c.Planets[planet.ID()]
+		stats.Arrival = make([]int, len(fields))
+		for i, field := range fields {
+			stats.Arrival[i] = c.arrivalTurn(field, stats)
+		}
+
+		mine, enemy := stats.Arrival[c.gameMap.MyID], unreachable
+		for i, turn := range stats.Arrival {
+			if i != c.gameMap.MyID && turn < enemy {
+				enemy = turn
+			}
+		}
+		stats.StaticValue = float64(enemy - mine)
+		halitedebug.Circle(stats, "territory", stats.Territory().String())
+	}
+}
+
+// arrivalTurn returns the number of turns needed to reach the docking range of the planet given the distance field of a player
+func (c *Commander) arrivalTurn(field []float64, planet *PlanetStats) int {
+	_, distance := c.dockRangeTile(field, planet)
+	if math.IsInf(distance, 1) {
+		return unreachable
+	}
+	return int(math.Ceil(distance / hlt.Constants.MaxSpeed))
+}
+
+// dockRangeTile returns the tile within the docking range of the planet with the lowest distance in the field
+func (c *Commander) dockRangeTile(field []float64, planet *PlanetStats) (tile *navigation.Tile, distance float64) {
+	distance = math.Inf(1)
+	for _, candidate := range c.dockRangeTiles(planet) {
+		if value := field[candidate.PathIndex()]; value < distance {
+			tile, distance = candidate, value
+		}
+	}
+	return tile, distance
+}
+
+// dockRangeTiles returns the tiles from where a ship can dock to the planet
+func (c *Commander) dockRangeTiles(planet *PlanetStats) []*navigation.Tile {
+	x, y, r := planet.Circle()
+	dockRange := r + hlt.Constants.DockRadius + hlt.Constants.ShipRadius
+
+	tiles := []*navigation.Tile{}
+	for _, tile := range c.Grid.TilesWithin(x, y, dockRange) {
+		if tile.Type != navigation.Blocked {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles
+}
+
+// sortByTerritory moves the lost planets to the end and the safe ones to the front keeping the order inside each group
+func sortByTerritory(planets []*PlanetStats) {
+	sort.SliceStable(planets, func(i, j int) bool {
+		return planets[i].Territory() < planets[j].Territory()
+	})
+}
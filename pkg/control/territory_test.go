@@ -0,0 +1,50 @@
+package control_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/control"
+)
+
+var _ = Describe("Territory", func() {
+	var commander *Commander
+
+	BeforeEach(func() {
+		commander = NewCommander()
+	})
+
+	It("Should mark planets that we reach first as safe", func() {
+		Expect(commander.Prepare(context.Background(), newMap(newShip(60, 80, 0, 0), newShip(10, 10, 1, 1)))).To(Succeed())
+
+		planet := commander.Planets[0]
+		Expect(planet.Arrival[0]).To(BeNumerically("<", planet.Arrival[1]))
+		Expect(planet.Territory()).To(Equal(SafeTerritory))
+		Expect(commander.TerritoryMap()).To(Equal(map[int]Territory{0: SafeTerritory}))
+	})
+	It("Should mark planets that the enemy reaches first as lost", func() {
+		Expect(commander.Prepare(context.Background(), newMap(newShip(10, 10, 0, 0), newShip(60, 80, 1, 1)))).To(Succeed())
+
+		Expect(commander.Planets[0].Territory()).To(Equal(LostTerritory))
+	})
+	It("Should mark planets at the same distance as contested", func() {
+		Expect(commander.Prepare(context.Background(), newMap(newShip(60, 80, 0, 0), newShip(80, 60, 1, 1)))).To(Succeed())
+
+		Expect(commander.Planets[0].StaticValue).To(Equal(0.0))
+		Expect(commander.Planets[0].Territory()).To(Equal(ContestedTerritory))
+	})
+	It("Should count the turns to reach the docking range", func() {
+		Expect(commander.Prepare(context.Background(), newMap(newShip(80, 60, 0, 0)))).To(Succeed())
+
+		// The docking range starts 10.5 units away from the ship
+		Expect(commander.Planets[0].Arrival[0]).To(Equal(2))
+	})
+	It("Should keep the partition of the first turn", func() {
+		Expect(commander.Prepare(context.Background(), newMap(newShip(60, 80, 0, 0), newShip(10, 10, 1, 1)))).To(Succeed())
+		commander.SetMap(newMap(newShip(10, 10, 0, 0), newShip(60, 80, 1, 1)), 1)
+
+		Expect(commander.Planets[0].Territory()).To(Equal(SafeTerritory))
+	})
+})
@@ -0,0 +1,165 @@
+package hlt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// Command is an order for a single ship that is sent to the engine at the end of the turn
+type Command interface {
+	fmt.Stringer
+	// Ship returns the ID of the ship that receives the order
+	Ship() int
+}
+
+// ThrustCommand accelerates the ship Magnitude units in the Angle direction, in degrees
+type ThrustCommand struct {
+	ShipID    int
+	Magnitude int
+	Angle     int
+}
+
+func (c ThrustCommand) Ship() int {
+	return c.ShipID
+}
+
+// Velocity returns the displacement of the ship during the turn. Drag is bigger than the
+// max acceleration so the velocity of the previous turn never carries over
+func (c ThrustCommand) Velocity() (x, y float64) {
+	angle := twoD.DegToRad(float64(c.Angle))
+	return float64(c.Magnitude) * math.Cos(angle), float64(c.Magnitude) * math.Sin(angle)
+}
+
+func (c ThrustCommand) String() string {
+	return "t " + strconv.Itoa(c.ShipID) + " " + strconv.Itoa(c.Magnitude) + " " + strconv.Itoa(c.Angle)
+}
+
+// DockCommand starts docking the ship to the planet
+type DockCommand struct {
+	ShipID   int
+	PlanetID int
+}
+
+func (c DockCommand) Ship() int {
+	return c.ShipID
+}
+
+func (c DockCommand) String() string {
+	return "d " + strconv.Itoa(c.ShipID) + " " + strconv.Itoa(c.PlanetID)
+}
+
+// UndockCommand releases a docked ship from its planet
+type UndockCommand struct {
+	ShipID int
+}
+
+func (c UndockCommand) Ship() int {
+	return c.ShipID
+}
+
+func (c UndockCommand) String() string {
+	return "u " + strconv.Itoa(c.ShipID)
+}
+
+// SerializeCommands encodes the commands in the format expected by the engine
+func SerializeCommands(commands []Command) string {
+	var b strings.Builder
+	for i, command := range commands {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(command.String())
+	}
+	return b.String()
+}
+
+var (
+	// ErrDuplicateCommand is returned when a ship already has a command this turn
+	ErrDuplicateCommand = errors.New("ship already has a command")
+	// ErrNotMyShip is returned when the ship does not exist or belongs to another player
+	ErrNotMyShip = errors.New("ship is not controlled by the player")
+	// ErrInvalidThrust is returned when the magnitude or the angle are out of the engine limits
+	ErrInvalidThrust = errors.New("invalid thrust")
+	// ErrUnknownPlanet is returned when the dock target does not exist
+	ErrUnknownPlanet = errors.New("unknown planet")
+	// ErrPlanetFull is returned when the dock target has no free spots for the player
+	ErrPlanetFull = errors.New("planet has no free docking spots")
+)
+
+// CommandError describes why a command was rejected by ValidateCommands
+type CommandError struct {
+	Command Command
+	Err     error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("command %q: %v", e.Command, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateCommands returns the commands that the engine will accept for the player gameMap.MyID
+// and an error for every command that was dropped.
+// A single invalid command makes the engine discard the whole turn, so it is better to lose only that one
+func ValidateCommands(gameMap Map, commands []Command) ([]Command, []error) {
+	valid := make([]Command, 0, len(commands))
+	var errs []error
+	reject := func(command Command, err error) {
+		errs = append(errs, &CommandError{Command: command, Err: err})
+	}
+
+	commanded := make(map[int]bool, len(commands))
+	docking := make(map[int]float64)
+	for _, command := range commands {
+		if commanded[command.Ship()] {
+			reject(command, ErrDuplicateCommand)
+			continue
+		}
+		ship, exist := gameMap.Ships[command.Ship()]
+		if !exist || ship.Owner() != gameMap.MyID {
+			reject(command, ErrNotMyShip)
+			continue
+		}
+
+		switch command := command.(type) {
+		case ThrustCommand:
+			if command.Magnitude < 0 || float64(command.Magnitude) > Constants.MaxSpeed ||
+				command.Angle < 0 || command.Angle >= 360 {
+				reject(command, ErrInvalidThrust)
+				continue
+			}
+		case DockCommand:
+			planet, exist := gameMap.planet(command.PlanetID)
+			if !exist {
+				reject(command, ErrUnknownPlanet)
+				continue
+			}
+			if planet.Owned != 0 && planet.Owner() != gameMap.MyID ||
+				planet.NumDockedShips+docking[planet.ID()] >= planet.NumDockingSpots {
+				reject(command, ErrPlanetFull)
+				continue
+			}
+			docking[planet.ID()]++
+		}
+
+		commanded[command.Ship()] = true
+		valid = append(valid, command)
+	}
+	return valid, errs
+}
+
+func (gameMap Map) planet(id int) (Planet, bool) {
+	for _, planet := range gameMap.Planets {
+		if planet.ID() == id {
+			return planet, true
+		}
+	}
+	return Planet{}, false
+}
@@ -0,0 +1,84 @@
+package hlt_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+var _ = Describe("Command", func() {
+	var (
+		gameMap Map
+		ship    Ship
+		enemy   Ship
+		planet  Planet
+	)
+
+	BeforeEach(func() {
+		ship = Ship{Entity: NewEntity(10, 10, 0.5, 255, 0, 0)}
+		enemy = Ship{Entity: NewEntity(50, 50, 0.5, 255, 1, 1)}
+		planet = Planet{Entity: NewEntity(20, 10, 5, 1275, 0, 0), NumDockingSpots: 1}
+		gameMap = Map{
+			MyID:    0,
+			Ships:   map[int]Ship{ship.ID(): ship, enemy.ID(): enemy, 2: {Entity: NewEntity(10, 20, 0.5, 255, 0, 2)}},
+			Planets: []Planet{planet},
+		}
+	})
+
+	It("Should serialize the commands in the engine format", func() {
+		commands := []Command{
+			ship.Thrust(7.9, -90.4),
+			ship.Dock(planet),
+			ship.Undock(),
+		}
+		Expect(SerializeCommands(commands)).To(Equal("t 0 7 270 d 0 0 u 0"))
+	})
+	It("Should keep valid commands", func() {
+		commands := []Command{ship.Thrust(7, 45), DockCommand{ShipID: 2, PlanetID: 0}}
+		valid, errs := ValidateCommands(gameMap, commands)
+		Expect(errs).To(BeEmpty())
+		Expect(valid).To(Equal(commands))
+	})
+	Context("Should drop invalid commands", func() {
+		expectRejected := func(command Command, expected error) {
+			commands := []Command{ship.Thrust(1, 0), command}
+			valid, errs := ValidateCommands(gameMap, commands)
+			Expect(valid).To(Equal(commands[:1]))
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0]).To(BeAssignableToTypeOf(&CommandError{}))
+			Expect(errs[0].(*CommandError).Err).To(Equal(expected))
+		}
+		It("duplicated ship", func() {
+			expectRejected(UndockCommand{ShipID: 0}, ErrDuplicateCommand)
+		})
+		It("enemy ship", func() {
+			expectRejected(ThrustCommand{ShipID: 1, Magnitude: 1}, ErrNotMyShip)
+		})
+		It("unknown ship", func() {
+			expectRejected(ThrustCommand{ShipID: 9, Magnitude: 1}, ErrNotMyShip)
+		})
+		It("too fast", func() {
+			expectRejected(ThrustCommand{ShipID: 2, Magnitude: 8}, ErrInvalidThrust)
+		})
+		It("negative angle", func() {
+			expectRejected(ThrustCommand{ShipID: 2, Magnitude: 1, Angle: -1}, ErrInvalidThrust)
+		})
+		It("unknown planet", func() {
+			expectRejected(DockCommand{ShipID: 2, PlanetID: 5}, ErrUnknownPlanet)
+		})
+	})
+	It("Should count the spots taken by other commands of the same turn", func() {
+		commands := []Command{ship.Dock(planet), DockCommand{ShipID: 2, PlanetID: 0}}
+		valid, errs := ValidateCommands(gameMap, commands)
+		Expect(valid).To(Equal(commands[:1]))
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].(*CommandError).Err).To(Equal(ErrPlanetFull))
+	})
+	It("Should not dock to enemy planets", func() {
+		gameMap.Planets[0] = Planet{Entity: NewEntity(20, 10, 5, 1275, 1, 0), NumDockingSpots: 3, Owned: 1}
+		_, errs := ValidateCommands(gameMap, []Command{ship.Dock(planet)})
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].(*CommandError).Err).To(Equal(ErrPlanetFull))
+	})
+})
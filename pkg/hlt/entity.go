@@ -35,6 +35,19 @@ type Entity struct {
 	id     int
 }
 
+// NewEntity creates an Entity, it is used by packages that need to build game states
+// without parsing an engine string
+func NewEntity(x, y, radius, health float64, owner, id int) Entity {
+	return Entity{
+		x:      x,
+		y:      y,
+		radius: radius,
+		health: health,
+		owner:  owner,
+		id:     id,
+	}
+}
+
 func (e Entity) Position() (x, y float64) {
 	return e.x, e.y
 }
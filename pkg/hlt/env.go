@@ -2,39 +2,85 @@ package hlt
 
 import (
 	"encoding/json"
+	"io/ioutil"
 )
 
-var Constants map[string]interface{}
+// GameConstants holds the rules of the game. The engine can run with
+// non-default values, so code must read them from here instead of hardcoding them
+type GameConstants struct {
+	AdditionalProductivity float64 `json:"ADDITIONAL_PRODUCTIVITY"`
+	BaseProductivity       float64 `json:"BASE_PRODUCTIVITY"`
+	BaseShipHealth         float64 `json:"BASE_SHIP_HEALTH"`
+	DockedShipRegeneration float64 `json:"DOCKED_SHIP_REGENERATION"`
+	DockRadius             float64 `json:"DOCK_RADIUS"`
+	DockTurns              int     `json:"DOCK_TURNS"`
+	Drag                   float64 `json:"DRAG"`
+	ExplosionRadius        float64 `json:"EXPLOSION_RADIUS"`
+	ExtraPlanets           int     `json:"EXTRA_PLANETS"`
+	InfiniteResources      bool    `json:"INFINITE_RESOURCES"`
+	MaxAcceleration        float64 `json:"MAX_ACCELERATION"`
+	MaxShipHealth          float64 `json:"MAX_SHIP_HEALTH"`
+	MaxSpeed               float64 `json:"MAX_SPEED"`
+	MaxTurns               int     `json:"MAX_TURNS"`
+	PlanetsPerPlayer       int     `json:"PLANETS_PER_PLAYER"`
+	ProductionPerShip      float64 `json:"PRODUCTION_PER_SHIP"`
+	ResourcesPerRadius     float64 `json:"RESOURCES_PER_RADIUS"`
+	ShipsPerPlayer         int     `json:"SHIPS_PER_PLAYER"`
+	ShipRadius             float64 `json:"SHIP_RADIUS"`
+	SpawnRadius            float64 `json:"SPAWN_RADIUS"`
+	WeaponCooldown         int     `json:"WEAPON_COOLDOWN"`
+	WeaponDamage           float64 `json:"WEAPON_DAMAGE"`
+	WeaponRadius           float64 `json:"WEAPON_RADIUS"`
+}
+
+// Constants used by the bot, they start with the default engine values
+var Constants = DefaultConstants()
+
+// DefaultConstants returns the values used by the engine when no overrides are given
+func DefaultConstants() GameConstants {
+	return GameConstants{
+		AdditionalProductivity: 6,
+		BaseProductivity:       6,
+		BaseShipHealth:         255,
+		DockedShipRegeneration: 0,
+		DockRadius:             4.0,
+		DockTurns:              5,
+		Drag:                   10.0,
+		ExplosionRadius:        10.0,
+		ExtraPlanets:           4,
+		InfiniteResources:      true,
+		MaxAcceleration:        7.0,
+		MaxShipHealth:          255,
+		MaxSpeed:               7.0,
+		MaxTurns:               300,
+		PlanetsPerPlayer:       6,
+		ProductionPerShip:      72,
+		ResourcesPerRadius:     144,
+		ShipsPerPlayer:         3,
+		ShipRadius:             0.5,
+		SpawnRadius:            2,
+		WeaponCooldown:         1,
+		WeaponDamage:           64,
+		WeaponRadius:           5.0,
+	}
+}
 
-func init() {
-	Constants = make(map[string]interface{})
-	err := json.Unmarshal([]byte(`{
-    "ADDITIONAL_PRODUCTIVITY": 6,
-    "BASE_PRODUCTIVITY": 6,
-    "BASE_SHIP_HEALTH": 255,
-    "DOCKED_SHIP_REGENERATION": 0,
-    "DOCK_RADIUS": 4.0,
-    "DOCK_TURNS": 5,
-    "DRAG": 10.0,
-    "EXPLOSION_RADIUS": 10.0,
-    "EXTRA_PLANETS": 4,
-    "INFINITE_RESOURCES": true,
-    "MAX_ACCELERATION": 7.0,
-    "MAX_SHIP_HEALTH": 255,
-    "MAX_SPEED": 7.0,
-    "MAX_TURNS": 300,
-    "PLANETS_PER_PLAYER": 6,
-    "PRODUCTION_PER_SHIP": 72,
-    "RESOURCES_PER_RADIUS": 144,
-    "SHIPS_PER_PLAYER": 3,
-    "SHIP_RADIUS": 0.5,
-    "SPAWN_RADIUS": 2,
-    "WEAPON_COOLDOWN": 1,
-    "WEAPON_DAMAGE": 64,
-    "WEAPON_RADIUS": 5.0
-}`), &Constants)
+// ParseConstants reads a JSON object with engine constants.
+// Missing keys keep the default value and unknown keys are ignored
+func ParseConstants(data []byte) (GameConstants, error) {
+	constants := DefaultConstants()
+	err := json.Unmarshal(data, &constants)
 	if err != nil {
-		panic(err)
+		return DefaultConstants(), err
 	}
+	return constants, nil
+}
 
+// LoadConstants reads the constants from a JSON file
+func LoadConstants(path string) (GameConstants, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return DefaultConstants(), err
+	}
+	return ParseConstants(data)
 }
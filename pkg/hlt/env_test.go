@@ -0,0 +1,57 @@
+package hlt_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+var _ = Describe("Constants", func() {
+	AfterEach(func() {
+		Constants = DefaultConstants()
+	})
+
+	It("Should override only the given values", func() {
+		constants, err := ParseConstants([]byte(`{"MAX_SPEED": 10.0, "DOCK_TURNS": 3, "UNKNOWN": 1}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(constants.MaxSpeed).To(BeNumerically("==", 10))
+		Expect(constants.DockTurns).To(Equal(3))
+		Expect(constants.WeaponRadius).To(Equal(DefaultConstants().WeaponRadius))
+	})
+	It("Should fail with invalid values", func() {
+		_, err := ParseConstants([]byte(`{"MAX_SPEED": "fast"}`))
+		Expect(err).To(HaveOccurred())
+	})
+	It("Should read the constants sent by the engine before the player tag", func() {
+		source := make(chan string, 3)
+		response := make(chan string, 1)
+		source <- `{"MAX_SPEED": 10.0, "DOCK_RADIUS": 2.0}`
+		source <- "1"
+		source <- "240 160"
+
+		conn := NewConnection("bot", source, response)
+		Expect(conn.PlayerTag).To(Equal(1))
+		Expect(Constants.MaxSpeed).To(BeNumerically("==", 10))
+		Expect(<-response).To(Equal("bot"))
+	})
+	It("Should wait for SendName to finish the initialization", func() {
+		source := make(chan string, 2)
+		response := make(chan string, 1)
+		source <- "0"
+		source <- "240 160"
+
+		conn := Handshake(source, response)
+		Expect(response).To(BeEmpty())
+		conn.SendName("bot")
+		Expect(<-response).To(Equal("bot"))
+	})
+	It("Should use the constants to check docking distance", func() {
+		ship := Ship{Entity: NewEntity(0, 0, 0.5, 255, 0, 0)}
+		planet := Planet{Entity: NewEntity(9, 0, 5, 1275, 0, 1), NumDockingSpots: 2}
+		Expect(ship.CanDock(planet)).To(BeTrue())
+
+		Constants.DockRadius = 2
+		Expect(ship.CanDock(planet)).To(BeFalse())
+	})
+})
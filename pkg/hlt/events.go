@@ -0,0 +1,164 @@
+package hlt
+
+import (
+	"fmt"
+)
+
+// Event is something that happened between two consecutive turns, see Diff
+type Event interface {
+	fmt.Stringer
+}
+
+// ShipSpawned is emitted for ships that were not in the previous turn
+type ShipSpawned struct {
+	Ship Ship
+}
+
+func (e ShipSpawned) String() string {
+	x, y := e.Ship.Position()
+	return fmt.Sprintf("ship %d of player %d spawned at (%.1f, %.1f)", e.Ship.ID(), e.Ship.Owner(), x, y)
+}
+
+// ShipDestroyed is emitted for ships that are gone, Ship holds their last known state
+type ShipDestroyed struct {
+	Ship Ship
+}
+
+func (e ShipDestroyed) String() string {
+	x, y := e.Ship.Position()
+	return fmt.Sprintf("ship %d of player %d destroyed at (%.1f, %.1f)", e.Ship.ID(), e.Ship.Owner(), x, y)
+}
+
+// ShipMoved is emitted when a ship position changes, DX and DY are the displacement since the previous turn
+type ShipMoved struct {
+	Ship   Ship
+	DX, DY float64
+}
+
+func (e ShipMoved) String() string {
+	return fmt.Sprintf("ship %d of player %d moved (%.1f, %.1f)", e.Ship.ID(), e.Ship.Owner(), e.DX, e.DY)
+}
+
+// DockingStarted is emitted when an undocked ship begins docking to a planet
+type DockingStarted struct {
+	Ship     Ship
+	PlanetID int
+}
+
+func (e DockingStarted) String() string {
+	return fmt.Sprintf("ship %d of player %d started docking to planet %d", e.Ship.ID(), e.Ship.Owner(), e.PlanetID)
+}
+
+// DockingFinished is emitted when a ship becomes docked and starts producing
+type DockingFinished struct {
+	Ship     Ship
+	PlanetID int
+}
+
+func (e DockingFinished) String() string {
+	return fmt.Sprintf("ship %d of player %d docked to planet %d", e.Ship.ID(), e.Ship.Owner(), e.PlanetID)
+}
+
+// PlanetCaptured is emitted when a planet gets a new owner
+type PlanetCaptured struct {
+	Planet Planet
+}
+
+func (e PlanetCaptured) String() string {
+	return fmt.Sprintf("planet %d captured by player %d", e.Planet.ID(), e.Planet.Owner())
+}
+
+// PlanetLost is emitted when the owner of a planet loses it. Planet holds the previous state
+type PlanetLost struct {
+	Planet Planet
+}
+
+func (e PlanetLost) String() string {
+	return fmt.Sprintf("planet %d lost by player %d", e.Planet.ID(), e.Planet.Owner())
+}
+
+// PlanetHealthChanged is emitted when a planet is damaged
+type PlanetHealthChanged struct {
+	Planet   Planet
+	Previous float64
+}
+
+func (e PlanetHealthChanged) String() string {
+	return fmt.Sprintf("planet %d health changed from %.0f to %.0f", e.Planet.ID(), e.Previous, e.Planet.Health())
+}
+
+// PlanetDestroyed is emitted for planets that are gone, Planet holds their last known state
+type PlanetDestroyed struct {
+	Planet Planet
+}
+
+func (e PlanetDestroyed) String() string {
+	return fmt.Sprintf("planet %d destroyed", e.Planet.ID())
+}
+
+// Diff compares two consecutive turns and returns what happened in between.
+// Events are sorted by player and ship order and then by planet order, so the result is deterministic
+func Diff(previous, current Map) []Event {
+	events := []Event{}
+
+	for _, player := range current.Players {
+		for _, ship := range player.Ships {
+			old, exist := previous.Ships[ship.ID()]
+			if !exist {
+				events = append(events, ShipSpawned{Ship: ship})
+				continue
+			}
+			events = appendShipEvents(events, old, ship)
+		}
+	}
+	for _, player := range previous.Players {
+		for _, ship := range player.Ships {
+			if _, exist := current.Ships[ship.ID()]; !exist {
+				events = append(events, ShipDestroyed{Ship: ship})
+			}
+		}
+	}
+
+	planets := make(map[int]Planet, len(current.Planets))
+	for _, planet := range current.Planets {
+		planets[planet.ID()] = planet
+	}
+	for _, old := range previous.Planets {
+		planet, exist := planets[old.ID()]
+		if !exist {
+			events = append(events, PlanetDestroyed{Planet: old})
+			continue
+		}
+		events = appendPlanetEvents(events, old, planet)
+	}
+	return events
+}
+
+func appendShipEvents(events []Event, old, ship Ship) []Event {
+	oldX, oldY := old.Position()
+	x, y := ship.Position()
+	if x != oldX || y != oldY {
+		events = append(events, ShipMoved{Ship: ship, DX: x - oldX, DY: y - oldY})
+	}
+	if old.DockingStatus == UNDOCKED && ship.DockingStatus != UNDOCKED {
+		events = append(events, DockingStarted{Ship: ship, PlanetID: ship.PlanetID})
+	}
+	if old.DockingStatus != DOCKED && ship.DockingStatus == DOCKED {
+		events = append(events, DockingFinished{Ship: ship, PlanetID: ship.PlanetID})
+	}
+	return events
+}
+
+func appendPlanetEvents(events []Event, old, planet Planet) []Event {
+	wasOwned, owned := old.Owned != 0, planet.Owned != 0
+	if wasOwned && (!owned || old.Owner() != planet.Owner()) {
+		events = append(events, PlanetLost{Planet: old})
+	}
+	if owned && (!wasOwned || old.Owner() != planet.Owner()) {
+		events = append(events, PlanetCaptured{Planet: planet})
+	}
+	if old.Health() != planet.Health() {
+		events = append(events, PlanetHealthChanged{Planet: planet, Previous: old.Health()})
+	}
+	return events
+}
@@ -0,0 +1,88 @@
+package hlt_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+var _ = Describe("Events", func() {
+	newMap := func(planets []Planet, ships ...Ship) Map {
+		gameMap := Map{
+			Players: []Player{{ID: 0}, {ID: 1}},
+			Ships:   make(map[int]Ship),
+			Planets: planets,
+		}
+		for _, ship := range ships {
+			player := &gameMap.Players[ship.Owner()]
+			player.Ships = append(player.Ships, ship)
+			gameMap.Ships[ship.ID()] = ship
+		}
+		return gameMap
+	}
+	newShip := func(x, y float64, owner, id int) Ship {
+		return Ship{Entity: NewEntity(x, y, 0.5, 255, owner, id)}
+	}
+	newPlanet := func(health float64, owned float64, owner int) Planet {
+		return Planet{Entity: NewEntity(50, 50, 5, health, owner, 0), NumDockingSpots: 2, Owned: owned}
+	}
+
+	It("Should report spawned, moved and destroyed ships", func() {
+		previous := newMap(nil, newShip(10, 10, 0, 0), newShip(20, 20, 1, 1))
+		current := newMap(nil, newShip(13, 6, 0, 0), newShip(30, 30, 1, 2))
+
+		events := Diff(previous, current)
+		Expect(events).To(Equal([]Event{
+			ShipMoved{Ship: current.Ships[0], DX: 3, DY: -4},
+			ShipSpawned{Ship: current.Ships[2]},
+			ShipDestroyed{Ship: previous.Ships[1]},
+		}))
+		Expect(events[0].String()).To(Equal("ship 0 of player 0 moved (3.0, -4.0)"))
+	})
+	It("Should not report ships that did not change", func() {
+		gameMap := newMap(nil, newShip(10, 10, 0, 0))
+		Expect(Diff(gameMap, gameMap)).To(BeEmpty())
+	})
+	It("Should report docking", func() {
+		undocked := newShip(10, 10, 0, 0)
+		docking := undocked
+		docking.DockingStatus = DOCKING
+		docked := undocked
+		docked.DockingStatus = DOCKED
+
+		Expect(Diff(newMap(nil, undocked), newMap(nil, docking))).To(Equal([]Event{
+			DockingStarted{Ship: docking},
+		}))
+		Expect(Diff(newMap(nil, docking), newMap(nil, docked))).To(Equal([]Event{
+			DockingFinished{Ship: docked},
+		}))
+	})
+	It("Should report planet ownership changes", func() {
+		neutral := newPlanet(1000, 0, 0)
+		mine := newPlanet(1000, 1, 0)
+		enemy := newPlanet(1000, 1, 1)
+
+		Expect(Diff(newMap([]Planet{neutral}), newMap([]Planet{mine}))).To(Equal([]Event{
+			PlanetCaptured{Planet: mine},
+		}))
+		Expect(Diff(newMap([]Planet{mine}), newMap([]Planet{enemy}))).To(Equal([]Event{
+			PlanetLost{Planet: mine},
+			PlanetCaptured{Planet: enemy},
+		}))
+		Expect(Diff(newMap([]Planet{enemy}), newMap([]Planet{neutral}))).To(Equal([]Event{
+			PlanetLost{Planet: enemy},
+		}))
+	})
+	It("Should report planet damage and destruction", func() {
+		planet := newPlanet(1000, 0, 0)
+		damaged := newPlanet(800, 0, 0)
+
+		Expect(Diff(newMap([]Planet{planet}), newMap([]Planet{damaged}))).To(Equal([]Event{
+			PlanetHealthChanged{Planet: damaged, Previous: 1000},
+		}))
+		Expect(Diff(newMap([]Planet{damaged}), newMap(nil))).To(Equal([]Event{
+			PlanetDestroyed{Planet: damaged},
+		}))
+	})
+})
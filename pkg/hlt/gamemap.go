@@ -14,6 +14,8 @@ type Map struct {
 	Players             []Player
 	Ships               map[int]Ship
 	Entities            []Entitier
+	// Index speeds up the queries over Entities, it is nil until BuildIndex is called
+	Index *SpatialIndex
 }
 
 // Player has an ID for establishing ownership, and a number of ships
@@ -42,7 +44,8 @@ func ParsePlayer(tokens []string) (Player, []string) {
 	return player, tokens
 }
 
-// ParseGameString from a slice of game state tokens
+// ParseGameString from a slice of game state tokens. Players with a repeated ID are skipped,
+// ParseGameStringStrict reports them
 func ParseGameString(c *Connection, gameString string) Map {
 	tokens := strings.Split(gameString, " ")
 	numPlayers, _ := strconv.Atoi(tokens[0])
@@ -61,6 +64,10 @@ func ParseGameString(c *Connection, gameString string) Map {
 	for i := 0; i < numPlayers; i++ {
 		player, tokensnew := ParsePlayer(tokens)
 		tokens = tokensnew
+		// Parsed players always have a Ships slice, only the empty slots have nil
+		if gameMap.Players[player.ID].Ships != nil {
+			continue
+		}
 		gameMap.Players[player.ID] = player
 		for j := 0; j < len(player.Ships); j++ {
 			ship := player.Ships[j]
@@ -83,6 +90,89 @@ func ParseGameString(c *Connection, gameString string) Map {
 	return gameMap
 }
 
+// ParsePlayerStrict reads a player and its ships from the game state tokens.
+// numPlayers is used to validate the player ID
+func ParsePlayerStrict(tokens *Tokens, numPlayers int) (Player, error) {
+	playerID, err := tokens.IntRange("player.id", 0, numPlayers-1)
+	if err != nil {
+		return Player{}, err
+	}
+	playerNumShips, err := tokens.Count("player.numShips", 10)
+	if err != nil {
+		return Player{}, err
+	}
+
+	player := Player{
+		ID:    playerID,
+		Ships: make([]Ship, 0, playerNumShips),
+	}
+	for i := 0; i < playerNumShips; i++ {
+		ship, err := ParseShipStrict(playerID, tokens)
+		if err != nil {
+			return Player{}, err
+		}
+		player.Ships = append(player.Ships, ship)
+	}
+	return player, nil
+}
+
+// ParseGameStringStrict works like ParseGameString but returns a *ParseError
+// instead of silently building a wrong map from a corrupted game string
+func ParseGameStringStrict(c *Connection, gameString string) (Map, error) {
+	tokens := NewTokens(gameString)
+	numPlayers, err := tokens.Count("numPlayers", 2)
+	if err != nil {
+		return Map{}, err
+	}
+
+	gameMap := Map{
+		MyID:     c.PlayerTag,
+		Width:    c.width,
+		Height:   c.height,
+		Planets:  nil,
+		Players:  make([]Player, numPlayers),
+		Ships:    make(map[int]Ship),
+		Entities: make([]Entitier, 0),
+	}
+
+	for i := 0; i < numPlayers; i++ {
+		position := tokens.pos
+		player, err := ParsePlayerStrict(tokens, numPlayers)
+		if err != nil {
+			return Map{}, err
+		}
+		// Parsed players always have a Ships slice, only the empty slots have nil
+		if gameMap.Players[player.ID].Ships != nil {
+			return Map{}, &ParseError{Position: position, Field: "player.id", Token: strconv.Itoa(player.ID), Err: ErrDuplicatePlayer}
+		}
+		gameMap.Players[player.ID] = player
+		for _, ship := range player.Ships {
+			gameMap.Entities = append(gameMap.Entities, ship.Entity)
+			gameMap.Ships[ship.id] = ship
+		}
+	}
+
+	numPlanets, err := tokens.Count("numPlanets", 11)
+	if err != nil {
+		return Map{}, err
+	}
+	gameMap.Planets = make([]Planet, 0, numPlanets)
+
+	for i := 0; i < numPlanets; i++ {
+		planet, err := ParsePlanetStrict(tokens)
+		if err != nil {
+			return Map{}, err
+		}
+		gameMap.Planets = append(gameMap.Planets, planet)
+		gameMap.Entities = append(gameMap.Entities, planet.Entity)
+	}
+
+	if err := tokens.End(); err != nil {
+		return Map{}, err
+	}
+	return gameMap, nil
+}
+
 type byX []Entity
 
 func (a byX) Len() int           { return len(a) }
@@ -96,6 +186,9 @@ func (a byY) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byY) Less(i, j int) bool { return a[i].y < a[j].y }
 
 func (gameMap Map) ObstaclesBetween(start twoD.Circler, end twoD.Positioner, ignoreIDs ...int) (bool, Entitier) {
+	if gameMap.Index != nil {
+		return gameMap.Index.ObstaclesBetween(start, end, ignoreIDs...)
+	}
 	return ObstaclesBetween(start, end, gameMap.Entities, ignoreIDs...)
 }
 
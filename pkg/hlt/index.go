@@ -0,0 +1,195 @@
+package hlt
+
+import (
+	"math"
+	"sort"
+
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// IndexCellSize is the bucket size used by Map.BuildIndex. A ship move spans at most two buckets
+const IndexCellSize = 8.0
+
+// SpatialIndex groups entities in a uniform grid of buckets so queries only look at the
+// entities around the area of interest. Every entity is stored in all the buckets that its circle touches.
+//
+// The index is built once per turn and it is not safe for concurrent queries
+type SpatialIndex struct {
+	entities      []Entitier
+	cellSize      float64
+	columns, rows int
+	cells         [][]int32
+
+	// seen avoids returning twice entities stored in several buckets
+	seen       []uint32
+	generation uint32
+	found      []int32
+	candidates []Entitier
+}
+
+// NewSpatialIndex builds an index for the entities that are inside a width x height map
+func NewSpatialIndex(width, height int, cellSize float64, entities []Entitier) *SpatialIndex {
+	index := &SpatialIndex{
+		entities: entities,
+		cellSize: cellSize,
+		columns:  int(math.Ceil(float64(width)/cellSize)) + 1,
+		rows:     int(math.Ceil(float64(height)/cellSize)) + 1,
+		seen:     make([]uint32, len(entities)),
+	}
+	index.cells = make([][]int32, index.columns*index.rows)
+	for i, entity := range entities {
+		x, y, r := entity.Circle()
+		minColumn, minRow, maxColumn, maxRow := index.cellRange(x-r, y-r, x+r, y+r)
+		for row := minRow; row <= maxRow; row++ {
+			for column := minColumn; column <= maxColumn; column++ {
+				cell := row*index.columns + column
+				index.cells[cell] = append(index.cells[cell], int32(i))
+			}
+		}
+	}
+	return index
+}
+
+// BuildIndex creates the Index for the current Entities, it must be called again after the map changes
+func (gameMap *Map) BuildIndex() {
+	gameMap.Index = NewSpatialIndex(gameMap.Width, gameMap.Height, IndexCellSize, gameMap.Entities)
+}
+
+func (index *SpatialIndex) cell(value float64, size int) int {
+	cell := int(math.Floor(value / index.cellSize))
+	if cell < 0 {
+		return 0
+	}
+	if cell >= size {
+		return size - 1
+	}
+	return cell
+}
+
+func (index *SpatialIndex) cellRange(minX, minY, maxX, maxY float64) (minColumn, minRow, maxColumn, maxRow int) {
+	return index.cell(minX, index.columns), index.cell(minY, index.rows),
+		index.cell(maxX, index.columns), index.cell(maxY, index.rows)
+}
+
+// collect stores in found the entities of the buckets in the range, sorted by their position in the entities slice
+func (index *SpatialIndex) collect(minColumn, minRow, maxColumn, maxRow int) {
+	index.generation++
+	index.found = index.found[:0]
+	for row := minRow; row <= maxRow; row++ {
+		for column := minColumn; column <= maxColumn; column++ {
+			for _, i := range index.cells[row*index.columns+column] {
+				if index.seen[i] == index.generation {
+					continue
+				}
+				index.seen[i] = index.generation
+				index.found = append(index.found, i)
+			}
+		}
+	}
+	// Insertion sort, the lists are short and sort.Slice allocates
+	for i := 1; i < len(index.found); i++ {
+		for j := i; j > 0 && index.found[j-1] > index.found[j]; j-- {
+			index.found[j-1], index.found[j] = index.found[j], index.found[j-1]
+		}
+	}
+}
+
+// Within returns the entities whose circle is at most radius units away from center
+func (index *SpatialIndex) Within(center twoD.Positioner, radius float64) []Entitier {
+	x, y := center.Position()
+	index.collect(index.cellRange(x-radius, y-radius, x+radius, y+radius))
+
+	result := []Entitier{}
+	for _, i := range index.found {
+		entity := index.entities[i]
+		_, _, r := entity.Circle()
+		if twoD.Distance(center, entity)-r <= radius {
+			result = append(result, entity)
+		}
+	}
+	return result
+}
+
+// Nearest returns up to k entities accepted by the filter sorted by the distance from position to their surface.
+// A nil filter accepts all the entities
+func (index *SpatialIndex) Nearest(position twoD.Positioner, k int, filter func(Entitier) bool) []Entitier {
+	type candidate struct {
+		entity   Entitier
+		distance float64
+	}
+	if k <= 0 {
+		return []Entitier{}
+	}
+	best := make([]candidate, 0, k+1)
+
+	x, y := position.Position()
+	column, row := index.cell(x, index.columns), index.cell(y, index.rows)
+	index.generation++
+	for ring := 0; ; ring++ {
+		// Buckets outside the scanned rings are at least this far from position
+		if len(best) == k && best[k-1].distance <= float64(ring-1)*index.cellSize {
+			break
+		}
+		if ring > index.columns && ring > index.rows {
+			break
+		}
+		for r := row - ring; r <= row+ring; r++ {
+			if r < 0 || r >= index.rows {
+				continue
+			}
+			step := 2 * ring
+			if r == row-ring || r == row+ring || step == 0 {
+				step = 1
+			}
+			for c := column - ring; c <= column+ring; c += step {
+				if c < 0 || c >= index.columns {
+					continue
+				}
+				for _, i := range index.cells[r*index.columns+c] {
+					if index.seen[i] == index.generation {
+						continue
+					}
+					index.seen[i] = index.generation
+					entity := index.entities[i]
+					if filter != nil && !filter(entity) {
+						continue
+					}
+					_, _, radius := entity.Circle()
+					distance := twoD.Distance(position, entity) - radius
+					at := sort.Search(len(best), func(j int) bool { return best[j].distance > distance })
+					if at == k {
+						continue
+					}
+					best = append(best, candidate{})
+					copy(best[at+1:], best[at:])
+					best[at] = candidate{entity: entity, distance: distance}
+					if len(best) > k {
+						best = best[:k]
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]Entitier, len(best))
+	for i, c := range best {
+		result[i] = c.entity
+	}
+	return result
+}
+
+// ObstaclesBetween works like the ObstaclesBetween function but only checks the entities close to the segment
+func (index *SpatialIndex) ObstaclesBetween(start twoD.Circler, end twoD.Positioner, ignoreIDs ...int) (bool, Entitier) {
+	x1, y1, margin := start.Circle()
+	x2, y2 := end.Position()
+	index.collect(index.cellRange(
+		math.Min(x1, x2)-margin, math.Min(y1, y2)-margin,
+		math.Max(x1, x2)+margin, math.Max(y1, y2)+margin,
+	))
+
+	index.candidates = index.candidates[:0]
+	for _, i := range index.found {
+		index.candidates = append(index.candidates, index.entities[i])
+	}
+	return ObstaclesBetween(start, end, index.candidates, ignoreIDs...)
+}
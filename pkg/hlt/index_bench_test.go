@@ -0,0 +1,96 @@
+package hlt_test
+
+import (
+	"testing"
+
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// loadMap parses the recorded 4 player late game turn, the one with more entities
+func loadMap(b *testing.B) hlt.Map {
+	parser := hlt.NewParser(&hlt.Connection{})
+	parser.Width, parser.Height = 384, 256
+	gameMap := hlt.Map{}
+	if err := parser.Parse(loadTurn(b, "testdata/late_game_4p.txt"), &gameMap); err != nil {
+		b.Fatal(err)
+	}
+	return gameMap
+}
+
+// segments returns a move at max speed for every ship, like GetWaypointForTurn checks every turn
+func segments(gameMap hlt.Map) ([]hlt.Ship, []twoD.Positioner) {
+	ships := []hlt.Ship{}
+	ends := []twoD.Positioner{}
+	for _, player := range gameMap.Players {
+		for _, ship := range player.Ships {
+			x, y := ship.Position()
+			ships = append(ships, ship)
+			ends = append(ends, twoD.NewPosition(x+hlt.Constants.MaxSpeed, y))
+		}
+	}
+	return ships, ends
+}
+
+func BenchmarkObstaclesBetween(b *testing.B) {
+	gameMap := loadMap(b)
+	ships, ends := segments(gameMap)
+
+	b.Run("linear", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j, ship := range ships {
+				hlt.ObstaclesBetween(ship, ends[j], gameMap.Entities, ship.ID())
+			}
+		}
+	})
+	b.Run("index", func(b *testing.B) {
+		gameMap.BuildIndex()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for j, ship := range ships {
+				gameMap.Index.ObstaclesBetween(ship, ends[j], ship.ID())
+			}
+		}
+	})
+}
+
+func BenchmarkBuildIndex(b *testing.B) {
+	gameMap := loadMap(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gameMap.BuildIndex()
+	}
+}
+
+func BenchmarkNearest(b *testing.B) {
+	gameMap := loadMap(b)
+	ships, _ := segments(gameMap)
+
+	b.Run("linear", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, ship := range ships {
+				best, nearest := 0.0, hlt.Entitier(nil)
+				for _, entity := range gameMap.Entities {
+					_, _, r := entity.Circle()
+					distance := twoD.Distance(ship, entity) - r
+					if nearest == nil || distance < best {
+						best, nearest = distance, entity
+					}
+				}
+			}
+		}
+	})
+	b.Run("index", func(b *testing.B) {
+		gameMap.BuildIndex()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, ship := range ships {
+				gameMap.Index.Nearest(ship, 1, nil)
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package hlt_test
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"sort"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+var _ = Describe("SpatialIndex", func() {
+	var (
+		gameMap Map
+		random  *rand.Rand
+	)
+
+	BeforeEach(func() {
+		data, err := ioutil.ReadFile("testdata/late_game_4p.txt")
+		Expect(err).ToNot(HaveOccurred())
+		parser := NewParser(&Connection{})
+		parser.Width, parser.Height = 384, 256
+		Expect(parser.Parse(strings.TrimSpace(string(data)), &gameMap)).To(Succeed())
+		gameMap.BuildIndex()
+		random = rand.New(rand.NewSource(1))
+	})
+
+	randomPosition := func() twoD.Positioner {
+		return twoD.NewPosition(random.Float64()*384, random.Float64()*256)
+	}
+	ids := func(entities []Entitier) []int {
+		result := make([]int, len(entities))
+		for i, entity := range entities {
+			result[i] = entity.ID()
+		}
+		return result
+	}
+
+	It("Should find the same obstacles as the linear search", func() {
+		collisions := 0
+		for _, ship := range gameMap.Ships {
+			angle := random.Float64() * 360
+			end := twoD.Rotate(twoD.NewPosition(7, 0), twoD.NewPosition(0, 0), angle)
+			x, y := ship.Position()
+			endX, endY := end.Position()
+			end = twoD.NewPosition(x+endX, y+endY)
+
+			blocked, collider := ObstaclesBetween(ship, end, gameMap.Entities, ship.ID())
+			indexBlocked, indexCollider := gameMap.ObstaclesBetween(ship, end, ship.ID())
+			Expect(indexBlocked).To(Equal(blocked))
+			Expect(indexCollider == collider).To(BeTrue())
+			if blocked {
+				collisions++
+			}
+		}
+		Expect(collisions).To(BeNumerically(">", 0))
+	})
+	It("Should find the entities within a radius", func() {
+		for i := 0; i < 100; i++ {
+			center := randomPosition()
+			radius := random.Float64() * 30
+
+			expected := []Entitier{}
+			for _, entity := range gameMap.Entities {
+				_, _, r := entity.Circle()
+				if twoD.Distance(center, entity)-r <= radius {
+					expected = append(expected, entity)
+				}
+			}
+			Expect(ids(gameMap.Index.Within(center, radius))).To(Equal(ids(expected)))
+		}
+	})
+	It("Should find the nearest entities", func() {
+		planets := func(entity Entitier) bool {
+			_, _, r := entity.Circle()
+			return r > Constants.ShipRadius
+		}
+		for i := 0; i < 100; i++ {
+			position := randomPosition()
+			expected := []Entitier{}
+			for _, entity := range gameMap.Entities {
+				if planets(entity) {
+					expected = append(expected, entity)
+				}
+			}
+			distance := func(entity Entitier) float64 {
+				_, _, r := entity.Circle()
+				return twoD.Distance(position, entity) - r
+			}
+			sort.SliceStable(expected, func(a, b int) bool { return distance(expected[a]) < distance(expected[b]) })
+
+			Expect(ids(gameMap.Index.Nearest(position, 3, planets))).To(Equal(ids(expected[:3])))
+		}
+	})
+	It("Should return all the entities when k is bigger than the total", func() {
+		nearest := gameMap.Index.Nearest(twoD.NewPosition(0, 0), len(gameMap.Entities)+10, nil)
+		Expect(nearest).To(HaveLen(len(gameMap.Entities)))
+		Expect(gameMap.Index.Nearest(twoD.NewPosition(0, 0), 0, nil)).To(BeEmpty())
+	})
+})
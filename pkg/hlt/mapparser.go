@@ -0,0 +1,147 @@
+package hlt
+
+import "sort"
+
+// Parser decodes game strings into an existing Map, reusing the slices and maps
+// it already holds so a turn can be parsed without allocations.
+//
+// The Map given to Parse is overwritten, callers that need the previous turn
+// must alternate between two Maps. If Parse fails the content of the Map is undefined.
+type Parser struct {
+	MyID, Width, Height int
+	tokens              Tokens
+	ids                 []int
+	// parsed marks the player IDs already read in the current game string
+	parsed []bool
+}
+
+// NewParser creates a parser for the game described by the connection
+func NewParser(c *Connection) *Parser {
+	return &Parser{
+		MyID:   c.PlayerTag,
+		Width:  c.width,
+		Height: c.height,
+	}
+}
+
+// Parse decodes gameString into gameMap. Entities holds pointers to the
+// ships and planets stored in gameMap instead of copies
+func (p *Parser) Parse(gameString string, gameMap *Map) error {
+	tokens := &p.tokens
+	tokens.Reset(gameString)
+
+	gameMap.MyID = p.MyID
+	gameMap.Width = p.Width
+	gameMap.Height = p.Height
+
+	numPlayers, err := tokens.Count("numPlayers", 2)
+	if err != nil {
+		return err
+	}
+	if cap(gameMap.Players) < numPlayers {
+		players := make([]Player, numPlayers)
+		copy(players, gameMap.Players)
+		gameMap.Players = players
+	}
+	gameMap.Players = gameMap.Players[:numPlayers]
+	for i := range gameMap.Players {
+		gameMap.Players[i].ID = i
+		if gameMap.Players[i].Ships == nil {
+			gameMap.Players[i].Ships = []Ship{}
+		}
+		gameMap.Players[i].Ships = gameMap.Players[i].Ships[:0]
+	}
+
+	if cap(p.parsed) < numPlayers {
+		p.parsed = make([]bool, numPlayers)
+	}
+	p.parsed = p.parsed[:numPlayers]
+	for i := range p.parsed {
+		p.parsed[i] = false
+	}
+	for i := 0; i < numPlayers; i++ {
+		playerID, err := tokens.IntRange("player.id", 0, numPlayers-1)
+		if err != nil {
+			return err
+		}
+		if p.parsed[playerID] {
+			return tokens.fail("player.id", tokens.last, ErrDuplicatePlayer)
+		}
+		p.parsed[playerID] = true
+		playerNumShips, err := tokens.Count("player.numShips", 10)
+		if err != nil {
+			return err
+		}
+		player := &gameMap.Players[playerID]
+		for j := 0; j < playerNumShips; j++ {
+			ship, err := ParseShipStrict(playerID, tokens)
+			if err != nil {
+				return err
+			}
+			player.Ships = append(player.Ships, ship)
+		}
+	}
+
+	numPlanets, err := tokens.Count("numPlanets", 11)
+	if err != nil {
+		return err
+	}
+	if cap(gameMap.Planets) < numPlanets {
+		planets := make([]Planet, numPlanets)
+		copy(planets, gameMap.Planets[:cap(gameMap.Planets)])
+		gameMap.Planets = planets
+	}
+	gameMap.Planets = gameMap.Planets[:numPlanets]
+	for i := range gameMap.Planets {
+		if err := parsePlanetInto(tokens, &gameMap.Planets[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tokens.End(); err != nil {
+		return err
+	}
+
+	p.updateShips(gameMap)
+
+	// Pointers are taken once all the slices have their final size
+	gameMap.Entities = gameMap.Entities[:0]
+	for i := range gameMap.Players {
+		ships := gameMap.Players[i].Ships
+		for j := range ships {
+			gameMap.Entities = append(gameMap.Entities, &ships[j].Entity)
+		}
+	}
+	for i := range gameMap.Planets {
+		gameMap.Entities = append(gameMap.Entities, &gameMap.Planets[i].Entity)
+	}
+	return nil
+}
+
+// updateShips refreshes gameMap.Ships. Ships are too big to be stored inline in a
+// map, so existing keys are overwritten instead of recreated to avoid allocations
+func (p *Parser) updateShips(gameMap *Map) {
+	if gameMap.Ships == nil {
+		gameMap.Ships = make(map[int]Ship)
+	}
+
+	p.ids = p.ids[:0]
+	for _, player := range gameMap.Players {
+		for _, ship := range player.Ships {
+			p.ids = append(p.ids, ship.id)
+		}
+	}
+	sort.Ints(p.ids)
+	for id := range gameMap.Ships {
+		index := sort.SearchInts(p.ids, id)
+		if index == len(p.ids) || p.ids[index] != id {
+			delete(gameMap.Ships, id)
+		}
+	}
+
+	for _, player := range gameMap.Players {
+		for _, ship := range player.Ships {
+			gameMap.Ships[ship.id] = ship
+		}
+	}
+}
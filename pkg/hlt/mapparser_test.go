@@ -0,0 +1,70 @@
+package hlt_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+var _ = Describe("Parser", func() {
+	var (
+		conn   *Connection
+		parser *Parser
+	)
+	BeforeEach(func() {
+		conn = &Connection{PlayerTag: 1}
+		parser = NewParser(conn)
+	})
+
+	It("Should decode the same map as ParseGameStringStrict", func() {
+		expected, err := ParseGameStringStrict(conn, validGameString)
+		Expect(err).ToNot(HaveOccurred())
+
+		gameMap := Map{}
+		Expect(parser.Parse(validGameString, &gameMap)).To(Succeed())
+		Expect(gameMap.MyID).To(Equal(1))
+		Expect(gameMap.Players).To(Equal(expected.Players))
+		Expect(gameMap.Ships).To(Equal(expected.Ships))
+		Expect(gameMap.Planets).To(Equal(expected.Planets))
+
+		Expect(gameMap.Entities).To(HaveLen(len(expected.Entities)))
+		for i, entity := range gameMap.Entities {
+			Expect(entity.ID()).To(Equal(expected.Entities[i].ID()))
+			x, y := entity.Position()
+			expectedX, expectedY := expected.Entities[i].Position()
+			Expect(x).To(Equal(expectedX))
+			Expect(y).To(Equal(expectedY))
+		}
+	})
+	It("Should reuse the map buffers", func() {
+		gameMap := Map{}
+		Expect(parser.Parse(validGameString, &gameMap)).To(Succeed())
+		ships := gameMap.Players[0].Ships
+		docked := gameMap.Planets[0].DockedShipIDs
+
+		Expect(parser.Parse(validGameString, &gameMap)).To(Succeed())
+		Expect(&gameMap.Players[0].Ships[0]).To(BeIdenticalTo(&ships[0]))
+		Expect(&gameMap.Planets[0].DockedShipIDs[0]).To(BeIdenticalTo(&docked[0]))
+	})
+	It("Should remove ships that are not in the new turn", func() {
+		gameMap := Map{}
+		Expect(parser.Parse(validGameString, &gameMap)).To(Succeed())
+		Expect(parser.Parse("2 0 0 1 0 0", &gameMap)).To(Succeed())
+		Expect(gameMap.Ships).To(BeEmpty())
+		Expect(gameMap.Entities).To(BeEmpty())
+		Expect(gameMap.Planets).To(BeEmpty())
+	})
+	It("Should fail on corrupted strings", func() {
+		gameMap := Map{}
+		err := parser.Parse("2 0 1 0 1O.0 20.0 255 0 0 0 0 0 0", &gameMap)
+		Expect(err).To(BeAssignableToTypeOf(&ParseError{}))
+	})
+	It("Should fail on repeated player IDs", func() {
+		gameMap := Map{}
+		err := parser.Parse("2 1 0 1 0 0", &gameMap)
+		Expect(err).To(BeAssignableToTypeOf(&ParseError{}))
+		Expect(err.(*ParseError).Err).To(Equal(ErrDuplicatePlayer))
+		Expect(err.(*ParseError).Token).To(Equal("1"))
+	})
+})
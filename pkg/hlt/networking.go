@@ -15,6 +15,7 @@ type Connection struct {
 	PlayerTag     int
 	reader        <-chan string
 	writer        chan<- string
+	parser        *Parser
 }
 
 func (c *Connection) sendString(input string) {
@@ -37,36 +38,83 @@ func (c *Connection) getInt() int {
 	return i
 }
 
+// getTag reads the player tag. Engines that run with custom rules send
+// a JSON line with the constants before it, which replaces the current Constants
+func (c *Connection) getTag() int {
+	line := c.getString()
+	if strings.HasPrefix(line, "{") {
+		constants, err := ParseConstants([]byte(line))
+		if err != nil {
+			log.Printf("Errored on engine constants, keeping current values: %v", err)
+		} else {
+			Constants = constants
+		}
+		return c.getInt()
+	}
+	i, err := strconv.Atoi(line)
+	if err != nil {
+		log.Printf("Errored on initial tag: %v", err)
+	}
+	return i
+}
+
 // NewConnection initializes a new connection for one of the bots
-// participating in a match
+// participating in a match. It sends the name right away, use Handshake
+// to read the initial map before
 func NewConnection(botName string, source <-chan string, response chan<- string) Connection {
+	conn := Handshake(source, response)
+	conn.SendName(botName)
+	return conn
+}
+
+// Handshake reads the player tag and the map size. The engine sends the initial map
+// and waits for SendName, the time until then can be used to prepare the game
+func Handshake(source <-chan string, response chan<- string) Connection {
 	conn := Connection{
 		reader: source,
 		writer: response,
 	}
-	conn.PlayerTag = conn.getInt()
+	conn.PlayerTag = conn.getTag()
 	sizeInfo := strings.Split(conn.getString(), " ")
 	width, _ := strconv.Atoi(sizeInfo[0])
 	height, _ := strconv.Atoi(sizeInfo[1])
 	conn.width = width
 	conn.height = height
-	conn.sendString(botName)
+	conn.parser = NewParser(&conn)
 	return conn
 }
 
-// UpdateMap decodes the current turn's game state from a string
-func (c *Connection) UpdateMap() (Map, time.Time) {
+// SendName finishes the initialization, the engine starts the first turn after it
+func (c *Connection) SendName(botName string) {
+	c.sendString(botName)
+}
+
+// UpdateMap decodes the current turn's game state from a string.
+// If the string is corrupted, the error is a *ParseError and the map must not be used
+func (c *Connection) UpdateMap() (Map, time.Time, error) {
+	gameMap := Map{}
+	turnStart, err := c.UpdateMapInto(&gameMap)
+	if err != nil {
+		return Map{}, turnStart, err
+	}
+	return gameMap, turnStart, nil
+}
+
+// UpdateMapInto works like UpdateMap but overwrites gameMap reusing its memory, see Parser
+func (c *Connection) UpdateMapInto(gameMap *Map) (time.Time, error) {
 	log.Printf("--- NEW TURN --- \n")
 	gameString := c.getString()
 	turnStart := time.Now()
-	gameMap := ParseGameString(c, gameString)
+	if err := c.parser.Parse(gameString, gameMap); err != nil {
+		return turnStart, err
+	}
 	log.Printf("    Parsed map in %s", time.Since(turnStart))
-	return gameMap, turnStart
+	return turnStart, nil
 }
 
 // SubmitCommands encodes the player's commands into a string
-func (c *Connection) SubmitCommands(commandQueue []string) {
-	commandString := strings.Join(commandQueue, " ")
+func (c *Connection) SubmitCommands(commandQueue []Command) {
+	commandString := SerializeCommands(commandQueue)
 	//log.Printf("Final string : %+v\n", commandString)
 	c.sendString(commandString)
 }
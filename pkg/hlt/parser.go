@@ -0,0 +1,180 @@
+package hlt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var (
+	// ErrMissingToken is returned when the game string ends before all the fields are read
+	ErrMissingToken = errors.New("missing token")
+	// ErrTrailingTokens is returned when there are tokens left after parsing the game string
+	ErrTrailingTokens = errors.New("unexpected trailing tokens")
+	// ErrOutOfRange is returned when a value is well formed but not valid for its field
+	ErrOutOfRange = errors.New("value out of range")
+	// ErrDuplicatePlayer is returned when two players of the game string have the same ID
+	ErrDuplicatePlayer = errors.New("duplicate player id")
+)
+
+// ParseError describes which token of the game string could not be parsed
+type ParseError struct {
+	// Position is the index of the token in the game string
+	Position int
+	// Field is the name of the value that was being parsed, like "ship.x"
+	Field string
+	// Token is the raw text, it is empty for missing tokens
+	Token string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("token %d (%s) %q: %v", e.Position, e.Field, e.Token, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Tokens reads a game string token by token, keeping track of the position for error reporting.
+// The string is scanned in place so reading tokens does not allocate
+type Tokens struct {
+	data   string
+	offset int
+	pos    int
+	total  int
+	last   string
+}
+
+// NewTokens prepares a game string to be read
+func NewTokens(gameString string) *Tokens {
+	t := &Tokens{}
+	t.Reset(gameString)
+	return t
+}
+
+// Reset starts reading a new game string
+func (t *Tokens) Reset(gameString string) {
+	t.data = gameString
+	t.offset = 0
+	t.pos = 0
+	t.last = ""
+	t.total = 0
+	for i := 0; i < len(gameString); {
+		i = skipSpaces(gameString, i)
+		if i == len(gameString) {
+			break
+		}
+		t.total++
+		i = skipToken(gameString, i)
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+func skipSpaces(data string, i int) int {
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+	return i
+}
+
+func skipToken(data string, i int) int {
+	for i < len(data) && !isSpace(data[i]) {
+		i++
+	}
+	return i
+}
+
+// Remaining returns the number of tokens not read yet
+func (t *Tokens) Remaining() int {
+	return t.total - t.pos
+}
+
+func (t *Tokens) next(field string) (string, error) {
+	if t.pos >= t.total {
+		return "", &ParseError{Position: t.pos, Field: field, Err: ErrMissingToken}
+	}
+	start := skipSpaces(t.data, t.offset)
+	t.offset = skipToken(t.data, start)
+	t.last = t.data[start:t.offset]
+	t.pos++
+	return t.last, nil
+}
+
+func (t *Tokens) fail(field, token string, err error) error {
+	if numErr, ok := err.(*strconv.NumError); ok {
+		err = numErr.Err
+	}
+	return &ParseError{Position: t.pos - 1, Field: field, Token: token, Err: err}
+}
+
+// Int reads the next token as an integer
+func (t *Tokens) Int(field string) (int, error) {
+	token, err := t.next(field)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, t.fail(field, token, err)
+	}
+	return value, nil
+}
+
+// IntRange reads the next token as an integer between min and max, both included
+func (t *Tokens) IntRange(field string, min, max int) (int, error) {
+	value, err := t.Int(field)
+	if err != nil {
+		return 0, err
+	}
+	if value < min || value > max {
+		return 0, t.fail(field, t.last, ErrOutOfRange)
+	}
+	return value, nil
+}
+
+// Float reads the next token as a finite float
+func (t *Tokens) Float(field string) (float64, error) {
+	token, err := t.next(field)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return 0, t.fail(field, token, err)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, t.fail(field, token, ErrOutOfRange)
+	}
+	return value, nil
+}
+
+// Count reads a number of items that take at least size tokens each.
+// It fails if there are not enough tokens left, which prevents huge allocations on corrupted input
+func (t *Tokens) Count(field string, size int) (int, error) {
+	value, err := t.Int(field)
+	if err != nil {
+		return 0, err
+	}
+	if value < 0 {
+		return 0, t.fail(field, t.last, ErrOutOfRange)
+	}
+	if value > t.Remaining()/size {
+		return 0, t.fail(field, t.last, ErrMissingToken)
+	}
+	return value, nil
+}
+
+// End checks that all the tokens have been read
+func (t *Tokens) End() error {
+	if t.Remaining() > 0 {
+		start := skipSpaces(t.data, t.offset)
+		token := t.data[start:skipToken(t.data, start)]
+		return &ParseError{Position: t.pos, Field: "end", Token: token, Err: ErrTrailingTokens}
+	}
+	return nil
+}
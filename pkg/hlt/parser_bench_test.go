@@ -0,0 +1,67 @@
+package hlt_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+// Late game turns recorded from simulated games between two and four commanders
+var recordedTurns = []string{
+	"testdata/late_game_2p.txt",
+	"testdata/late_game_4p.txt",
+}
+
+func loadTurn(b *testing.B, path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func BenchmarkParseGameString(b *testing.B) {
+	conn := &hlt.Connection{}
+	for _, path := range recordedTurns {
+		gameString := loadTurn(b, path)
+		b.Run(path, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				hlt.ParseGameString(conn, gameString)
+			}
+		})
+	}
+}
+
+func BenchmarkParseGameStringStrict(b *testing.B) {
+	conn := &hlt.Connection{}
+	for _, path := range recordedTurns {
+		gameString := loadTurn(b, path)
+		b.Run(path, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := hlt.ParseGameStringStrict(conn, gameString); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	parser := hlt.NewParser(&hlt.Connection{})
+	for _, path := range recordedTurns {
+		gameString := loadTurn(b, path)
+		b.Run(path, func(b *testing.B) {
+			gameMap := hlt.Map{}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := parser.Parse(gameString, &gameMap); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
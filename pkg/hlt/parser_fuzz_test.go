@@ -0,0 +1,47 @@
+//go:build go1.18
+// +build go1.18
+
+package hlt_test
+
+import (
+	"testing"
+
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+// FuzzParseGameStringStrict checks that corrupted game strings never panic and
+// that every accepted string produces a consistent map
+func FuzzParseGameStringStrict(f *testing.F) {
+	f.Add(validGameString)
+	f.Add("0 0")
+	f.Add("1 0 0 0")
+	f.Add("1 0 1 0 10.0 20.0 255 0 0 3 0 0 0 0")
+
+	conn := &hlt.Connection{}
+	f.Fuzz(func(t *testing.T, gameString string) {
+		gameMap, err := hlt.ParseGameStringStrict(conn, gameString)
+		if err != nil {
+			if _, ok := err.(*hlt.ParseError); !ok {
+				t.Fatalf("expected *ParseError, got %T", err)
+			}
+			return
+		}
+		ships := 0
+		for _, player := range gameMap.Players {
+			ships += len(player.Ships)
+		}
+		if len(gameMap.Entities) != ships+len(gameMap.Planets) {
+			t.Fatalf("entities %d, ships %d, planets %d", len(gameMap.Entities), ships, len(gameMap.Planets))
+		}
+	})
+}
+
+// FuzzParseShipStrict checks that a single ship never panics the parser
+func FuzzParseShipStrict(f *testing.F) {
+	f.Add("0 10.0 20.0 255 0 0 0 0 0 0")
+	f.Add("0 10.0 20.0 255 0 0 4 0 0 0")
+
+	f.Fuzz(func(t *testing.T, tokens string) {
+		hlt.ParseShipStrict(0, hlt.NewTokens(tokens))
+	})
+}
@@ -0,0 +1,97 @@
+package hlt_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+const validGameString = "2 " +
+	"0 1 0 10.0 20.0 255 0 0 0 0 0 0 " +
+	"1 1 1 30.0 40.0 255 0 0 2 0 5 1 " +
+	"1 0 50.0 50.0 1275 5 2 0 720 1 1 1 1"
+
+func parseError(err error) *ParseError {
+	Expect(err).To(HaveOccurred())
+	parseErr, ok := err.(*ParseError)
+	Expect(ok).To(BeTrue(), "error must be a *ParseError, got %T", err)
+	return parseErr
+}
+
+var _ = Describe("Parser", func() {
+	var conn *Connection
+	BeforeEach(func() {
+		conn = &Connection{PlayerTag: 0}
+	})
+
+	It("Should parse the same map as ParseGameString", func() {
+		gameMap, err := ParseGameStringStrict(conn, validGameString)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gameMap).To(Equal(ParseGameString(conn, validGameString)))
+		Expect(gameMap.Ships[1].DockingStatus).To(Equal(DOCKED))
+		Expect(gameMap.Planets[0].DockedShipIDs).To(Equal([]int{1}))
+	})
+	It("Should report missing tokens", func() {
+		_, err := ParseGameStringStrict(conn, validGameString[:len(validGameString)-2])
+		parseErr := parseError(err)
+		Expect(parseErr.Err).To(Equal(ErrMissingToken))
+		Expect(parseErr.Field).To(Equal("planet.numDockedShips"))
+		Expect(parseErr.Position).To(Equal(36))
+	})
+	It("Should report the field that is not a number", func() {
+		_, err := ParseGameStringStrict(conn, "2 0 1 0 1O.0 20.0 255 0 0 0 0 0 0")
+		parseErr := parseError(err)
+		Expect(parseErr.Field).To(Equal("ship.x"))
+		Expect(parseErr.Position).To(Equal(4))
+		Expect(parseErr.Token).To(Equal("1O.0"))
+	})
+	It("Should reject unknown docking status", func() {
+		_, err := ParseGameStringStrict(conn, "1 0 1 0 10.0 20.0 255 0 0 7 0 0 0 0")
+		parseErr := parseError(err)
+		Expect(parseErr.Field).To(Equal("ship.dockingStatus"))
+		Expect(parseErr.Err).To(Equal(ErrOutOfRange))
+	})
+	It("Should reject negative counts", func() {
+		_, err := ParseGameStringStrict(conn, "1 0 -1 0")
+		parseErr := parseError(err)
+		Expect(parseErr.Field).To(Equal("player.numShips"))
+		Expect(parseErr.Err).To(Equal(ErrOutOfRange))
+	})
+	It("Should reject player IDs out of range", func() {
+		_, err := ParseGameStringStrict(conn, "1 3 0 0")
+		parseErr := parseError(err)
+		Expect(parseErr.Field).To(Equal("player.id"))
+	})
+	It("Should reject repeated player IDs", func() {
+		_, err := ParseGameStringStrict(conn, "2 0 0 0 0 0")
+		parseErr := parseError(err)
+		Expect(parseErr.Field).To(Equal("player.id"))
+		Expect(parseErr.Err).To(Equal(ErrDuplicatePlayer))
+		Expect(parseErr.Position).To(Equal(3))
+	})
+	It("Should keep the first player with a repeated ID in ParseGameString", func() {
+		gameMap := ParseGameString(conn, "2 0 1 0 10.0 20.0 255 0 0 0 0 0 0 0 1 5 30.0 40.0 255 0 0 0 0 0 0 0")
+		Expect(gameMap.Players[0].Ships).To(HaveLen(1))
+		Expect(gameMap.Players[0].Ships[0].ID()).To(Equal(0))
+		Expect(gameMap.Players[1].Ships).To(BeNil())
+		Expect(gameMap.Ships).To(HaveLen(1))
+	})
+	It("Should reject counts bigger than the remaining tokens", func() {
+		_, err := ParseGameStringStrict(conn, "1 0 99999999999 0")
+		parseErr := parseError(err)
+		Expect(parseErr.Field).To(Equal("player.numShips"))
+		Expect(parseErr.Err).To(Equal(ErrMissingToken))
+	})
+	It("Should reject non finite values", func() {
+		_, err := ParseGameStringStrict(conn, "1 0 1 0 NaN 20.0 255 0 0 0 0 0 0 0")
+		parseErr := parseError(err)
+		Expect(parseErr.Field).To(Equal("ship.x"))
+	})
+	It("Should reject trailing tokens", func() {
+		_, err := ParseGameStringStrict(conn, validGameString+" 3")
+		parseErr := parseError(err)
+		Expect(parseErr.Err).To(Equal(ErrTrailingTokens))
+		Expect(parseErr.Position).To(Equal(38))
+	})
+})
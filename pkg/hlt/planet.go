@@ -1,6 +1,9 @@
 package hlt
 
-import "strconv"
+import (
+	"math"
+	"strconv"
+)
 
 // Planet object from which Halite is mined
 type Planet struct {
@@ -54,3 +57,64 @@ func ParsePlanet(tokens []string) (Planet, []string) {
 	}
 	return planet, tokens[11+int(planetNumDockedShips):]
 }
+
+// ParsePlanetStrict reads a planet from the game state tokens, failing on any malformed or out of range value
+func ParsePlanetStrict(tokens *Tokens) (Planet, error) {
+	planet := Planet{}
+	if err := parsePlanetInto(tokens, &planet); err != nil {
+		return Planet{}, err
+	}
+	return planet, nil
+}
+
+// parsePlanetInto overwrites planet with the next planet in tokens, reusing the DockedShipIDs buffer
+func parsePlanetInto(tokens *Tokens, planet *Planet) error {
+	var err error
+	*planet = Planet{
+		DockedShipIDs: planet.DockedShipIDs[:0],
+	}
+
+	read := func(field string, value *float64) {
+		if err == nil {
+			*value, err = tokens.Float(field)
+		}
+	}
+
+	planet.id, err = tokens.IntRange("planet.id", 0, math.MaxInt32)
+	read("planet.x", &planet.x)
+	read("planet.y", &planet.y)
+	read("planet.health", &planet.health)
+	read("planet.radius", &planet.radius)
+	read("planet.numDockingSpots", &planet.NumDockingSpots)
+	read("planet.currentProduction", &planet.CurrentProduction)
+	read("planet.remainingResources", &planet.RemainingResources)
+	if err != nil {
+		return err
+	}
+
+	owned, err := tokens.IntRange("planet.owned", 0, 1)
+	if err != nil {
+		return err
+	}
+	planet.Owned = float64(owned)
+
+	planet.owner, err = tokens.IntRange("planet.owner", 0, math.MaxInt32)
+	if err != nil {
+		return err
+	}
+
+	numDockedShips, err := tokens.Count("planet.numDockedShips", 1)
+	if err != nil {
+		return err
+	}
+	planet.NumDockedShips = float64(numDockedShips)
+
+	for i := 0; i < numDockedShips; i++ {
+		dockedShipID, err := tokens.IntRange("planet.dockedShipID", 0, math.MaxInt32)
+		if err != nil {
+			return err
+		}
+		planet.DockedShipIDs = append(planet.DockedShipIDs, dockedShipID)
+	}
+	return nil
+}
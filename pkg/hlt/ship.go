@@ -1,7 +1,6 @@
 package hlt
 
 import (
-	"fmt"
 	"math"
 	"strconv"
 
@@ -37,7 +36,7 @@ func ParseShip(playerID int, tokens []string) (Ship, []string) {
 	shipEntity := Entity{
 		x:      shipX,
 		y:      shipY,
-		radius: .5,
+		radius: Constants.ShipRadius,
 		health: shipHealth,
 		owner:  playerID,
 		id:     shipID,
@@ -56,8 +55,46 @@ func ParseShip(playerID int, tokens []string) (Ship, []string) {
 	return ship, tokens[10:]
 }
 
-// Thrust generates a string describing the ship's intension to move during the current turn
-func (ship Ship) Thrust(magnitude float64, angle float64) string {
+// ParseShipStrict reads a ship from the game state tokens, failing on any malformed or out of range value
+func ParseShipStrict(playerID int, tokens *Tokens) (Ship, error) {
+	var err error
+	ship := Ship{}
+	ship.owner = playerID
+	ship.radius = Constants.ShipRadius
+
+	read := func(field string, value *float64) {
+		if err == nil {
+			*value, err = tokens.Float(field)
+		}
+	}
+
+	ship.id, err = tokens.IntRange("ship.id", 0, math.MaxInt32)
+	read("ship.x", &ship.x)
+	read("ship.y", &ship.y)
+	read("ship.health", &ship.health)
+	read("ship.velX", &ship.VelX)
+	read("ship.velY", &ship.VelY)
+	if err != nil {
+		return Ship{}, err
+	}
+
+	dockingStatus, err := tokens.IntRange("ship.dockingStatus", int(UNDOCKED), int(UNDOCKING))
+	if err != nil {
+		return Ship{}, err
+	}
+	ship.DockingStatus = DockingStatus(dockingStatus)
+
+	ship.PlanetID, err = tokens.IntRange("ship.planetID", 0, math.MaxInt32)
+	read("ship.dockingProgress", &ship.DockingProgress)
+	read("ship.weaponCooldown", &ship.WeaponCooldown)
+	if err != nil {
+		return Ship{}, err
+	}
+	return ship, nil
+}
+
+// Thrust generates a command describing the ship's intension to move during the current turn
+func (ship Ship) Thrust(magnitude float64, angle float64) ThrustCommand {
 	var boundedAngle int
 	if angle > 0.0 {
 		boundedAngle = int(math.Floor(angle + .5))
@@ -65,29 +102,37 @@ func (ship Ship) Thrust(magnitude float64, angle float64) string {
 		boundedAngle = int(math.Ceil(angle - .5))
 	}
 	boundedAngle = ((boundedAngle % 360) + 360) % 360
-	return fmt.Sprintf("t %s %s %s", strconv.Itoa(ship.id), strconv.Itoa(int(magnitude)), strconv.Itoa(boundedAngle))
+	return ThrustCommand{ShipID: ship.id, Magnitude: int(magnitude), Angle: boundedAngle}
 }
 
-// Dock generates a string describing the ship's intension to dock during the current turn
-func (ship Ship) Dock(planet Planet) string {
-	return fmt.Sprintf("d %s %s", strconv.Itoa(ship.id), strconv.Itoa(planet.id))
+// Dock generates a command describing the ship's intension to dock during the current turn
+func (ship Ship) Dock(planet Planet) DockCommand {
+	return DockCommand{ShipID: ship.id, PlanetID: planet.id}
 }
 
-// Undock generates a string describing the ship's intension to undock during the current turn
-func (ship Ship) Undock() string {
-	return fmt.Sprintf("u %s", strconv.Itoa(ship.id))
+// Undock generates a command describing the ship's intension to undock during the current turn
+func (ship Ship) Undock() UndockCommand {
+	return UndockCommand{ShipID: ship.id}
 }
 
 // NavigateBasic demonstrates how the player might move ships through space
-func (ship Ship) NavigateBasic(target twoD.Positioner) string {
+func (ship Ship) NavigateBasic(target twoD.Positioner) ThrustCommand {
 	distance := ship.CalculateDistanceTo(target)
 	angle := ship.CalculateAngleTo(target)
-	speed := 7.0 //TODO: get from environment
-
-	speed = math.Min(speed, distance)
+	speed := math.Min(Constants.MaxSpeed, distance)
 	return ship.Thrust(speed, angle)
 }
 
+// Motion returns the movement of the ship during the turn if it receives the command.
+// Only thrust commands move the ship, a nil command means the ship stays still
+func (ship Ship) Motion(command Command) twoD.SweptCircle {
+	var velX, velY float64
+	if thrust, ok := command.(ThrustCommand); ok && ship.DockingStatus == UNDOCKED {
+		velX, velY = thrust.Velocity()
+	}
+	return twoD.NewSweptCircle(ship, velX, velY)
+}
+
 // CanDock indicates that a ship is close enough to a given planet to dock
 func (ship Ship) CanDock(planet Planet) bool {
 	owner := planet.Owner()
@@ -99,7 +144,7 @@ func (ship Ship) CanDock(planet Planet) bool {
 	}
 	dist := ship.CalculateDistanceTo(planet)
 	_, _, radius := planet.Circle()
-	return dist <= (ship.radius + radius + 4)
+	return dist <= (ship.radius + radius + Constants.DockRadius)
 }
 
 // IntToDockingStatus converts an int to a DockingStatus
@@ -0,0 +1,149 @@
+package navigation
+
+import (
+	"container/heap"
+	"math"
+)
+
+// DistanceField returns, for every tile, the length in map units of the shortest path from the closest source.
+// Paths move in 8 directions and go around Blocked tiles, the other tile types are ignored.
+// Unreachable tiles are +Inf and sources outside the grid are skipped
+func (g *Grid) DistanceField(sources ...Positioner) []float64 {
+	distances := make([]float64, len(g.Tiles))
+	for i := range distances {
+		distances[i] = math.Inf(1)
+	}
+
+	open := &distanceQueue{}
+	for _, source := range sources {
+		tile := g.GetTile(source.Position())
+		if tile == nil {
+			continue
+		}
+		index := g.index(tile)
+		distances[index] = 0
+		heap.Push(open, distanceItem{index: index})
+	}
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(distanceItem)
+		if current.distance > distances[current.index] {
+			continue
+		}
+		x, y := current.index%g.Width, current.index/g.Width
+		for _, step := range neighborSteps {
+			nx, ny := x+step.dx, y+step.dy
+			if nx < 0 || nx >= g.Width || ny < 0 || ny >= g.Height {
+				continue
+			}
+			next := ny*g.Width + nx
+			if g.Tiles[next].Type == Blocked {
+				continue
+			}
+			distance := current.distance + step.cost/g.Scale
+			if distance < distances[next] {
+				distances[next] = distance
+				heap.Push(open, distanceItem{index: next, distance: distance})
+			}
+		}
+	}
+	return distances
+}
+
+func (g *Grid) index(tile *Tile) int {
+	return int(tile.Y)*g.Width + int(tile.X)
+}
+
+var neighborSteps = []struct {
+	dx, dy int
+	cost   float64
+}{
+	{0, -1, 1}, {0, 1, 1}, {-1, 0, 1}, {1, 0, 1},
+	{-1, -1, math.Sqrt2}, {1, -1, math.Sqrt2}, {-1, 1, math.Sqrt2}, {1, 1, math.Sqrt2},
+}
+
+type distanceItem struct {
+	index    int
+	distance float64
+}
+
+type distanceQueue []distanceItem
+
+func (q distanceQueue) Len() int            { return len(q) }
+func (q distanceQueue) Less(i, j int) bool  { return q[i].distance < q[j].distance }
+func (q distanceQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *distanceQueue) Push(x interface{}) { *q = append(*q, x.(distanceItem)) }
+func (q *distanceQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// push and pop work like heap.Push and heap.Pop without boxing the items
+func (q *distanceQueue) push(item distanceItem) {
+	*q = append(*q, item)
+	queue := *q
+	for i := len(queue) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if queue[parent].distance <= queue[i].distance {
+			break
+		}
+		queue[parent], queue[i] = queue[i], queue[parent]
+		i = parent
+	}
+}
+
+func (q *distanceQueue) pop() distanceItem {
+	queue := *q
+	item := queue[0]
+	last := len(queue) - 1
+	queue[0] = queue[last]
+	queue = queue[:last]
+	for i := 0; ; {
+		smallest, left, right := i, 2*i+1, 2*i+2
+		if left < last && queue[left].distance < queue[smallest].distance {
+			smallest = left
+		}
+		if right < last && queue[right].distance < queue[smallest].distance {
+			smallest = right
+		}
+		if smallest == i {
+			break
+		}
+		queue[i], queue[smallest] = queue[smallest], queue[i]
+		i = smallest
+	}
+	*q = queue
+	return item
+}
+
+// Descend follows the distance field from the tile that contains the position down to the closest source.
+// The path includes both ends and it is nil if the position is unreachable
+func (g *Grid) Descend(field []float64, from Positioner) []*Tile {
+	tile := g.GetTile(from.Position())
+	if tile == nil || math.IsInf(field[g.index(tile)], 1) {
+		return nil
+	}
+	index := g.index(tile)
+	path := []*Tile{tile}
+	for field[index] > 0 {
+		x, y := index%g.Width, index/g.Width
+		next := index
+		for _, step := range neighborSteps {
+			nx, ny := x+step.dx, y+step.dy
+			if nx < 0 || nx >= g.Width || ny < 0 || ny >= g.Height {
+				continue
+			}
+			if neighbor := ny*g.Width + nx; field[neighbor] < field[next] {
+				next = neighbor
+			}
+		}
+		if next == index {
+			break
+		}
+		index = next
+		path = append(path, g.Tiles[index])
+	}
+	return path
+}
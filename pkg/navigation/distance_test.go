@@ -0,0 +1,48 @@
+package navigation_test
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+var _ = Describe("DistanceField", func() {
+	It("Should measure straight and diagonal moves", func() {
+		grid := navigation.NewGrid(10, 5)
+
+		field := grid.DistanceField(grid.GetTile(0, 0))
+
+		Expect(field[0]).To(Equal(0.0))
+		Expect(field[9]).To(Equal(9.0))
+		Expect(field[4*10+4]).To(BeNumerically("~", 4*math.Sqrt2, 1e-9))
+	})
+	It("Should go around blocked tiles", func() {
+		grid := navigation.NewGrid(10, 5)
+		for y := 0.0; y < 4; y++ {
+			grid.GetTile(4, y).Type = navigation.Blocked
+		}
+
+		field := grid.DistanceField(grid.GetTile(0, 0))
+
+		Expect(field[4]).To(Equal(math.Inf(1)))
+		Expect(field[5]).To(BeNumerically(">", 5))
+	})
+	It("Should use the closest source", func() {
+		grid := navigation.NewGrid(10, 1)
+
+		field := grid.DistanceField(grid.GetTile(0, 0), grid.GetTile(9, 0))
+
+		Expect(field[3]).To(Equal(3.0))
+		Expect(field[7]).To(Equal(2.0))
+	})
+	It("Should leave unreachable tiles at infinity when there are no sources", func() {
+		grid := navigation.NewGrid(3, 3)
+
+		field := grid.DistanceField()
+
+		Expect(field[4]).To(Equal(math.Inf(1)))
+	})
+})
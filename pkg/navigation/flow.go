@@ -0,0 +1,126 @@
+package navigation
+
+import (
+	"container/heap"
+	"math"
+)
+
+// FlowField holds the cost to reach a target region from every tile of a grid and the next step towards it.
+// It is built once with a reverse Dijkstra search and then every pilot that goes to the region reads its path in O(1)
+// per step. The field only stores indexes, so it can be read with the grids of the following turns
+type FlowField struct {
+	Width, Height int
+	// Cost is the cost of the cheapest path from every tile to the region, +Inf for the unreachable tiles
+	Cost []float64
+	next []int
+	// obstacles are the Blocked and Ship tiles when the field was built, see Changed
+	obstacles []bool
+	// fire is the enemy fire of the tiles when the field was built, see Stale
+	fire []float64
+}
+
+// FlowField finds the cheapest paths from every tile to the closest target, with the same costs as Path.
+// Reservations are ignored because the field is shared by several pilots
+func (g *Grid) FlowField(targets ...Positioner) *FlowField {
+	field := &FlowField{
+		Width:     g.Width,
+		Height:    g.Height,
+		Cost:      make([]float64, len(g.Tiles)),
+		next:      make([]int, len(g.Tiles)),
+		obstacles: make([]bool, len(g.Tiles)),
+		fire:      append([]float64(nil), g.Influence.Enemy...),
+	}
+	weights := make([]float64, len(g.Tiles))
+	for i, tile := range g.Tiles {
+		field.Cost[i] = math.Inf(1)
+		field.next[i] = -1
+		field.obstacles[i] = tile.obstacle()
+		weights[i] = tile.weight()
+	}
+
+	open := &distanceQueue{}
+	for _, target := range targets {
+		tile := g.GetTile(target.Position())
+		if tile == nil || tile.Type == Blocked {
+			continue
+		}
+		index := g.index(tile)
+		field.Cost[index] = 0
+		heap.Push(open, distanceItem{index: index})
+	}
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(distanceItem)
+		if current.distance > field.Cost[current.index] {
+			continue
+		}
+		x, y := current.index%g.Width, current.index/g.Width
+		for _, step := range neighborSteps {
+			nx, ny := x+step.dx, y+step.dy
+			if nx < 0 || nx >= g.Width || ny < 0 || ny >= g.Height {
+				continue
+			}
+			previous := ny*g.Width + nx
+			if g.Tiles[previous].Type == Blocked {
+				continue
+			}
+			// The path goes from the neighbor to the current tile, so it pays the weight of the current one
+			cost := current.distance + step.cost*weights[current.index]
+			if cost < field.Cost[previous] {
+				field.Cost[previous] = cost
+				field.next[previous] = current.index
+				heap.Push(open, distanceItem{index: previous, distance: cost})
+			}
+		}
+	}
+	return field
+}
+
+// Next returns the next tile towards the region, nil when the tile is unreachable or it is already in the region
+func (f *FlowField) Next(tile *Tile) *Tile {
+	next := f.next[tile.PathIndex()]
+	if next < 0 {
+		return nil
+	}
+	return tile.Grid.Tiles[next]
+}
+
+// Path follows the field from the tile for the given number of steps or until it gets to the region.
+// The path starts with the tile
+func (f *FlowField) Path(from *Tile, steps int) []*Tile {
+	path := []*Tile{from}
+	for tile := f.Next(from); tile != nil && len(path) <= steps; tile = f.Next(tile) {
+		path = append(path, tile)
+	}
+	return path
+}
+
+// Changed returns the fraction of the tiles of the grid that became or stopped being Blocked or Ship tiles since the
+// field was built, like destroyed planets or moved ships. The enemy fire and the other costs change every turn
+// and they are not counted
+func (f *FlowField) Changed(grid *Grid) float64 {
+	changed := 0
+	for i, tile := range grid.Tiles {
+		if tile.obstacle() != f.obstacles[i] {
+			changed++
+		}
+	}
+	return float64(changed) / float64(len(grid.Tiles))
+}
+
+// Stale reports if the enemy fire on any tile of the path, read from the field, is different on the grid than when
+// the field was built. Changed does not count the fire, so a stale path ignores the current threat
+func (f *FlowField) Stale(grid *Grid, path []*Tile) bool {
+	for _, tile := range path {
+		index := tile.PathIndex()
+		if grid.Influence.Enemy[index] != f.fire[index] {
+			return true
+		}
+	}
+	return false
+}
+
+// obstacle tells if the tile is one of the tiles that Changed and the signatures of the Hierarchy look at
+func (t *Tile) obstacle() bool {
+	return t.Type == Blocked || t.Type == Ship
+}
@@ -0,0 +1,91 @@
+package navigation_test
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+var _ = Describe("FlowField", func() {
+	It("Should have the cost of the paths found by A*", func() {
+		grid := obstacleGrid(120, 80)
+		to := grid.GetTile(110, 5)
+
+		field := grid.FlowField(to)
+
+		for _, from := range [][2]float64{{0, 0}, {0, 40}, {60, 79}, {10, 70}} {
+			_, expected, found, _, _ := grid.Path(context.Background(), grid.GetTile(from[0], from[1]), to, -1)
+			Expect(found).To(BeTrue())
+			Expect(field.Cost[grid.GetTile(from[0], from[1]).PathIndex()]).To(BeNumerically("~", expected, 1e-9))
+		}
+	})
+	It("Should follow the steps down to the region", func() {
+		grid := obstacleGrid(120, 80)
+		region := []navigation.Positioner{grid.GetTile(100, 60), grid.GetTile(101, 60), grid.GetTile(100, 61)}
+		field := grid.FlowField(region...)
+		from := grid.GetTile(3, 4)
+
+		path := field.Path(from, math.MaxInt32)
+
+		Expect(path[0]).To(Equal(from))
+		Expect(region).To(ContainElement(path[len(path)-1]))
+		Expect(field.Next(path[len(path)-1])).To(BeNil())
+		cost := 0.0
+		for i := 1; i < len(path); i++ {
+			Expect(path[i].Type).NotTo(Equal(navigation.Blocked))
+			cost += path[i-1].PathNeighborCost(path[i])
+		}
+		Expect(cost).To(BeNumerically("~", field.Cost[from.PathIndex()], 1e-9))
+	})
+	It("Should stop after the steps", func() {
+		grid := navigation.NewGrid(40, 10)
+		field := grid.FlowField(grid.GetTile(39, 5))
+
+		Expect(field.Path(grid.GetTile(0, 5), 10)).To(HaveLen(11))
+	})
+	It("Should not reach tiles enclosed by Blocked tiles", func() {
+		grid := navigation.NewGrid(20, 20)
+		grid.Paint(15, 15, 3, navigation.Blocked)
+		grid.GetTile(15, 15).Type = navigation.Empty
+		field := grid.FlowField(grid.GetTile(0, 0))
+
+		Expect(math.IsInf(field.Cost[grid.GetTile(15, 15).PathIndex()], 1)).To(BeTrue())
+		Expect(field.Next(grid.GetTile(15, 15))).To(BeNil())
+	})
+	It("Should be read with the grids of the following turns", func() {
+		grid := navigation.NewGrid(30, 20)
+		field := grid.FlowField(grid.GetTile(25, 10))
+
+		next := navigation.NewGrid(30, 20)
+		Expect(field.Changed(next)).To(Equal(0.0))
+		Expect(field.Next(next.GetTile(5, 10)).Grid).To(Equal(next))
+
+		next.PaintShip(10, 10, 0)
+		Expect(field.Changed(next)).To(BeNumerically("~", 5.0/600, 1e-9))
+	})
+	It("Should tell the paths where the enemy fire changed", func() {
+		grid := navigation.NewGrid(30, 20)
+		field := grid.FlowField(grid.GetTile(25, 10))
+		path := field.Path(grid.GetTile(0, 10), 30)
+		Expect(field.Stale(grid, path)).To(BeFalse())
+
+		grid.Influence.AddShip(15, 2, 1, 2, 4, true)
+		Expect(field.Stale(grid, path)).To(BeFalse())
+
+		grid.Influence.AddShip(15, 10, 1, 2, 4, true)
+		Expect(field.Stale(grid, path)).To(BeTrue())
+	})
+	It("Should not count the enemy fire as a change", func() {
+		grid := navigation.NewGrid(30, 20)
+		field := grid.FlowField(grid.GetTile(25, 10))
+
+		grid.Influence.AddShip(15, 10, 1, 6, 13, true)
+		grid.Paint(5, 5, 3, navigation.ShotRange)
+
+		Expect(field.Changed(grid)).To(Equal(0.0))
+	})
+})
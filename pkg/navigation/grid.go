@@ -2,24 +2,67 @@ package navigation
 
 import (
 	"bytes"
+	"context"
 	log "github.com/sirupsen/logrus"
 	"io/ioutil"
 	"math"
+	"sync"
 
 	"github.com/metalblueberry/halite-bot/pkg/astar"
 )
 
+// Grid covers the map with square tiles. Tiles are centred on the integer grid coordinates, Tile.X and Tile.Y,
+// and the Scale sets how many of them fit in a unit of the map. Positions outside the grid package are always
+// map coordinates, ToGrid and ToWorld transform between both
 type Grid struct {
 	Width, Height int
-	Tiles         []*Tile
+	// Scale is the number of tiles per unit of the map, above 1 the grid is finer than the map and below 1 coarser
+	Scale float64
+	Tiles []*Tile
+	// Influence adds the enemy fire power to the cost of the tiles
+	Influence *Influence
+	// Weighter gives the weight of the tiles to Path and to the other searches, it combines the layers with the
+	// DefaultWeights unless it is replaced
+	Weighter Weighter
+	// layers are the cost layers of the tiles, see Layer
+	layers [LayerCount][]float64
+	// revision counts the changes of the layers, see Invalidate
+	revision int
+	// Pathfinder is the algorithm used by Path, AStar by default
+	Pathfinder Pathfinder
+	// Searchers keeps the A* buffers used by Path, grids of the same size can share them
+	Searchers *Searchers
+	// Reservations are the tiles reserved by other pilots, the searches avoid them when it is not nil
+	Reservations *Reservations
+	// obstacles are the Blocked circles painted by PaintPlanet, in map coordinates
+	obstacles []obstacle
 }
 
+// NewGrid creates a grid with a tile per unit of the map
 func NewGrid(Width, Height int) *Grid {
+	return newGrid(Width, Height, 1)
+}
+
+// NewScaledGrid creates a grid that covers a map of the given size with scale tiles per unit
+func NewScaledGrid(width, height int, scale float64) *Grid {
+	return newGrid(int(math.Ceil(float64(width)*scale)), int(math.Ceil(float64(height)*scale)), scale)
+}
+
+func newGrid(Width, Height int, scale float64) *Grid {
 	grid := &Grid{
-		Width:  Width,
-		Height: Height,
-		Tiles:  make([]*Tile, Height*Width, Height*Width),
+		Width:     Width,
+		Height:    Height,
+		Scale:     scale,
+		Tiles:     make([]*Tile, Height*Width, Height*Width),
+		Searchers: &Searchers{},
+	}
+	grid.Influence = NewInfluence(Width, Height)
+	grid.Influence.Scale = scale
+	for layer := range grid.layers {
+		grid.layers[layer] = make([]float64, Width*Height)
 	}
+	grid.layers[EnemyFireLayer], grid.layers[FriendlyTrafficLayer] = grid.Influence.Enemy, grid.Influence.Friendly
+	grid.Weighter = grid.Weighting(DefaultWeights)
 	for index := range grid.Tiles {
 		grid.Tiles[index] = &Tile{
 			Grid: grid,
@@ -30,6 +73,16 @@ func NewGrid(Width, Height int) *Grid {
 	return grid
 }
 
+// ToGrid transforms a position of the map to grid coordinates
+func (g *Grid) ToGrid(x, y float64) (float64, float64) {
+	return x * g.Scale, y * g.Scale
+}
+
+// ToWorld transforms grid coordinates to a position of the map
+func (g *Grid) ToWorld(x, y float64) (float64, float64) {
+	return x / g.Scale, y / g.Scale
+}
+
 func (g *Grid) PaintShip(X float64, Y float64, shotRange float64) {
 	g.Paint(X, Y, shotRange, ShotRange)
 	g.Paint(X, Y, 1.0, Ship)
@@ -38,41 +91,57 @@ func (g *Grid) PaintShip(X float64, Y float64, shotRange float64) {
 func (g *Grid) PaintPlanet(X float64, Y float64, radius float64) {
 	g.Paint(X, Y, radius+3, SafeMargin)
 	g.Paint(X, Y, radius+1, Blocked)
+	g.obstacles = append(g.obstacles, obstacle{X: X, Y: Y, R: radius + 1})
 }
 
+// Paint sets the type of the tiles whose centre is within the circle, ShotRange tiles stack up to ShotRange3.
+// The type is also recorded in its layer, so the cost of the tiles keeps every type painted on them
 func (g *Grid) Paint(X float64, Y float64, radius float64, value TileType) {
-	i := X - math.Ceil(radius)
-	j := Y - math.Ceil(radius)
-
-	for i := math.Max(i, 0); i < float64(g.Width) && i < X+math.Ceil(radius)*2; i++ {
-		for j := math.Max(j, 0); j < float64(g.Height) && j < Y+math.Ceil(radius)*2; j++ {
-			x := X - i
-			y := Y - j
-			if math.Sqrt(x*x+y*y) <= radius {
-				tile := g.GetTileSafe(i, j)
-				switch value {
-				case ShotRange:
-					switch tile.Type {
-					case Empty:
-						tile.Type = value
-					case ShotRange:
-						tile.Type = ShotRange2
-					case ShotRange2:
-						tile.Type = ShotRange3
-					}
-				default:
-					tile.Type = value
-				}
+	for _, tile := range g.TilesWithin(X, Y, radius) {
+		g.paint(tile, value)
+		switch value {
+		case ShotRange:
+			switch tile.Type {
+			case Empty:
+				tile.Type = value
+			case ShotRange:
+				tile.Type = ShotRange2
+			case ShotRange2:
+				tile.Type = ShotRange3
 			}
+		default:
+			tile.Type = value
 		}
 	}
 }
 
+// TilesWithin returns the tiles of the grid whose centre is within the circle
+func (g *Grid) TilesWithin(X, Y, radius float64) []*Tile {
+	x, y := g.ToGrid(X, Y)
+	r := radius * g.Scale
+	minX, maxX := math.Max(math.Ceil(x-r), 0), math.Min(math.Floor(x+r), float64(g.Width-1))
+	minY, maxY := math.Max(math.Ceil(y-r), 0), math.Min(math.Floor(y+r), float64(g.Height-1))
+
+	tiles := []*Tile{}
+	for j := minY; j <= maxY; j++ {
+		for i := minX; i <= maxX; i++ {
+			if math.Hypot(x-i, y-j) <= r {
+				tiles = append(tiles, g.Tiles[int(j)*g.Width+int(i)])
+			}
+		}
+	}
+	return tiles
+}
+
+// GetTile returns the tile closest to the position of the map, nil if the position is outside the grid
 func (g *Grid) GetTile(x, y float64) *Tile {
-	if x < 0 || x >= float64(g.Width) || y < 0 || y >= float64(g.Height) {
+	i, j := g.ToGrid(x, y)
+	if i < 0 || i >= float64(g.Width) || j < 0 || j >= float64(g.Height) {
 		return nil
 	}
-	return g.Tiles[int(int(math.Round(y))*g.Width+int(math.Round(x)))]
+	// The positions on the far half of the last tiles round out of the grid
+	column, row := math.Min(math.Round(i), float64(g.Width-1)), math.Min(math.Round(j), float64(g.Height-1))
+	return g.Tiles[int(row)*g.Width+int(column)]
 }
 
 func (g *Grid) GetTileSafe(x, y float64) *Tile {
@@ -84,24 +153,94 @@ func (g *Grid) GetTileSafe(x, y float64) *Tile {
 }
 
 func (g *Grid) SetTile(tile *Tile) {
-	x, y := tile.Position()
+	x, y := tile.X, tile.Y
 	if x < 0 || x >= float64(g.Width) || y < 0 || y >= float64(g.Height) {
 		log.Panicf("Index out of range \nx:%f y:%f\nw:%d h:%d\n", x, y, g.Width, g.Height)
 	}
 	g.Tiles[int(int(y)*g.Width+int(x))] = tile
 }
 
-func (g *Grid) Path(from, to *Tile, iterations int) (path []*Tile, distance float64, found bool, bestPath []*Tile) {
-	result, distance, found, bestResult := astar.Path(from, to, iterations)
-	path = make([]*Tile, len(result), len(result))
-	for i, step := range result {
-		path[i] = step.(*Tile)
+// Searchers hands an A* searcher to each concurrent search and keeps them for the next ones. Unlike a sync.Pool
+// the searchers are not dropped by the garbage collector. The searches on grids with reservations have a copy of
+// the tiles per turn, so searchers of several sizes are kept. It is safe for concurrent use
+type Searchers struct {
+	mu   sync.Mutex
+	free []*astar.Searcher
+}
+
+// get returns a free searcher for graphs of the given size or a new one
+func (s *Searchers) get(size int) *astar.Searcher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.free) - 1; i >= 0; i-- {
+		if searcher := s.free[i]; searcher.Size() == size {
+			s.free = append(s.free[:i], s.free[i+1:]...)
+			return searcher
+		}
 	}
-	bestPath = make([]*Tile, len(bestResult), len(bestResult))
-	for i, step := range bestResult {
-		bestPath[i] = step.(*Tile)
+	return astar.NewSearcher(size)
+}
+
+// put returns the searcher once the search is done
+func (s *Searchers) put(searcher *astar.Searcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.free = append(s.free, searcher)
+}
+
+// Path finds a path between the tiles with the Pathfinder of the grid. The search stops after the iterations or when the context is done,
+// bestPath holds the path to the closest tile to the goal found until then. ThetaStar paths only hold the waypoints,
+// the tiles where the path changes direction, every other Pathfinder returns all the tiles of the path.
+// On grids with Reservations a tile repeated in a row is a turn waiting on it, see Reservations.
+// It is safe to search paths concurrently as long as the grid is not modified
+func (g *Grid) Path(ctx context.Context, from, to *Tile, iterations int) (path []*Tile, distance float64, found bool, bestPath []*Tile, stats astar.Stats) {
+	return g.PathWeighted(ctx, nil, from, to, iterations)
+}
+
+// PathWeighted works like Path but the weight of the tiles is given by the weighter instead of the one of the grid.
+// A nil weighter uses the one of the grid
+func (g *Grid) PathWeighted(ctx context.Context, weighter Weighter, from, to *Tile, iterations int) (path []*Tile, distance float64, found bool, bestPath []*Tile, stats astar.Stats) {
+	// The searches with reservations run on the moments of the tiles, see schedule
+	var start, goal astar.Indexed = from, to
+	size := len(g.Tiles)
+	schedule := g.Reservations.schedule(to)
+	if schedule != nil {
+		start, goal = schedule.at(from, 0), schedule.at(to, 0)
+		size *= g.Reservations.Window + 1
+	}
+	searcher := g.Searchers.get(size)
+	defer g.Searchers.put(searcher)
+
+	// The plain search reads the costs from the tiles, that use the Weighter of the grid
+	plain := weighter == nil
+	if plain {
+		weighter = g.Weighter
+	}
+	if g.Pathfinder == ThetaStar {
+		result, distance, found, bestResult, stats := searcher.PathWith(ctx, anyAngle{grid: g, weighter: weighter, schedule: schedule}, start, goal, iterations)
+		return tiles(result), distance, found, tiles(bestResult), stats
+	}
+	if g.Pathfinder == JumpPoint {
+		jumps := newJumpPoints(g, weighter, schedule)
+		result, distance, found, bestResult, stats := searcher.PathWith(ctx, jumps, start, goal, iterations)
+		return jumps.interpolate(result), distance, found, jumps.interpolate(bestResult), stats
+	}
+
+	if schedule != nil || !plain {
+		result, distance, found, bestResult, stats := searcher.PathWith(ctx, cooperative{grid: g, weighter: weighter, schedule: schedule}, start, goal, iterations)
+		return tiles(result), distance, found, tiles(bestResult), stats
+	}
+
+	result, distance, found, bestResult, stats := searcher.Path(ctx, from, to, iterations)
+	return tiles(result), distance, found, tiles(bestResult), stats
+}
+
+func tiles(path []astar.Pather) []*Tile {
+	result := make([]*Tile, len(path), len(path))
+	for i, step := range path {
+		result[i] = tileOf(step)
 	}
-	return path, distance, found, bestPath
+	return result
 }
 
 func (g *Grid) String() string {
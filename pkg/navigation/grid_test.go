@@ -1,6 +1,7 @@
 package navigation_test
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"regexp"
@@ -68,6 +69,54 @@ var _ = Describe("Grid", func() {
 			}
 		})
 	})
+	Describe("When scaled", func() {
+		It("Should transform between map and grid coordinates", func() {
+			grid := navigation.NewScaledGrid(100, 50, 2)
+			Expect(grid.Width).To(Equal(200))
+			Expect(grid.Height).To(Equal(100))
+
+			tile := grid.GetTile(10.2, 5.4)
+			Expect(tile.X).To(Equal(20.0))
+			Expect(tile.Y).To(Equal(11.0))
+			x, y := tile.Position()
+			Expect(x).To(Equal(10.0))
+			Expect(y).To(Equal(5.5))
+		})
+		It("Should cover the whole map with coarse tiles", func() {
+			grid := navigation.NewScaledGrid(100, 50, 0.5)
+			Expect(grid.Width).To(Equal(50))
+			Expect(grid.Height).To(Equal(25))
+
+			Expect(grid.GetTile(99.9, 49.9)).To(Equal(grid.Tiles[len(grid.Tiles)-1]))
+			Expect(grid.GetTile(100, 20)).To(BeNil())
+			Expect(grid.GetTile(-0.1, 20)).To(BeNil())
+		})
+		It("Should paint the same area of the map at any scale", func() {
+			for _, scale := range []float64{0.5, 1, 2, 4} {
+				grid := navigation.NewScaledGrid(40, 40, scale)
+				grid.PaintPlanet(20, 20, 5)
+
+				Expect(grid.GetTile(20, 20).Type).To(Equal(navigation.Blocked), "scale %f", scale)
+				Expect(grid.GetTile(12.5, 20).Type).NotTo(Equal(navigation.Blocked), "scale %f", scale)
+				Expect(grid.GetTile(20, 30).Type).To(Equal(navigation.Empty), "scale %f", scale)
+			}
+		})
+		It("Should paint circles with fractional centres next to the borders", func() {
+			grid := navigation.NewGrid(10, 10)
+			Expect(func() { grid.Paint(9.7, 9.6, 2, navigation.Blocked) }).NotTo(Panic())
+			Expect(grid.GetTile(9.7, 9.6)).To(Equal(grid.GetTile(9, 9)))
+			Expect(grid.GetTile(9, 9).Type).To(Equal(navigation.Blocked))
+		})
+		It("Should find paths made of map positions", func() {
+			grid := navigation.NewScaledGrid(20, 10, 2)
+			path, _, found, _, _ := grid.Path(context.Background(), grid.GetTile(1, 5), grid.GetTile(18, 5), 100)
+
+			Expect(found).To(BeTrue())
+			x, y := path[len(path)-1].Position()
+			Expect(x).To(Equal(18.0))
+			Expect(y).To(Equal(5.0))
+		})
+	})
 	Describe("When printed as string", func() {
 		It("Should return an empty ASCII map", func() {
 			grid := navigation.NewGrid(4, 2)
@@ -131,7 +180,7 @@ var _ = Describe("Grid", func() {
 			grid := navigation.NewGrid(10, 3)
 			start := grid.GetTile(0, 1)
 			end := grid.GetTile(9, 1)
-			path, distance, found, _ := grid.Path(start, end, 10)
+			path, distance, found, _, _ := grid.Path(context.Background(), start, end, 10)
 
 			for _, step := range path {
 				step.Type = navigation.Walked
@@ -156,7 +205,7 @@ var _ = Describe("Grid", func() {
 			grid := navigation.NewGrid(20, 3)
 			start := grid.GetTile(0, 1)
 			end := grid.GetTile(19, 1)
-			path, distance, found, _ := grid.Path(start, end, 10)
+			path, distance, found, _, _ := grid.Path(context.Background(), start, end, 10)
 
 			for _, step := range path {
 				step.Type = navigation.Walked
@@ -187,7 +236,7 @@ var _ = Describe("Grid", func() {
 			grid.Paint(X, Y, radius+1, navigation.SafeMargin)
 			start := grid.GetTile(0, 3)
 			end := grid.GetTile(10, 3)
-			path, distance, found, _ := grid.Path(start, end, 200)
+			path, distance, found, _, _ := grid.Path(context.Background(), start, end, 200)
 
 			for _, step := range path {
 				step.Type = navigation.Walked
@@ -228,7 +277,7 @@ var _ = Describe("Grid", func() {
 
 			start := grid.GetTile(9, 0)
 			end := grid.GetTile(9, 14)
-			path, distance, found, _ := grid.Path(start, end, 200)
+			path, distance, found, _, _ := grid.Path(context.Background(), start, end, 200)
 
 			for _, step := range path {
 				step.Type = navigation.Walked
@@ -261,6 +310,17 @@ var _ = Describe("Grid", func() {
 			Expect(distance).To(BeNumerically(">", 0))
 
 		})
+		It("Should stop when the context is done", func() {
+			grid := navigation.NewGrid(200, 100)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, _, found, bestPath, stats := grid.Path(ctx, grid.GetTile(0, 0), grid.GetTile(199, 99), 10000)
+
+			Expect(found).To(BeFalse())
+			Expect(bestPath).NotTo(BeEmpty())
+			Expect(stats.Expanded).To(Equal(0))
+		})
 		// Is working, but the test is not
 		PIt("Should return the best posible path", func() {
 			grid := navigation.NewGrid(19, 15)
@@ -269,7 +329,7 @@ var _ = Describe("Grid", func() {
 
 			start := grid.GetTile(9, 0)
 			end := grid.GetTile(9, 14)
-			path, distance, found, bestPath := grid.Path(start, end, 40)
+			path, distance, found, bestPath, _ := grid.Path(context.Background(), start, end, 40)
 
 			for _, step := range path {
 				step.Type = navigation.Walked
@@ -311,7 +371,7 @@ var _ = Describe("Grid", func() {
 	// 		grid := navigation.NewGrid(10, 5)
 	// 		start := grid.GetTile(1, 2)
 	// 		end := grid.GetTile(8, 2)
-	// 		path, distance, found, _ := grid.Path(start, end, 200)
+	// 		path, distance, found, _, _ := grid.Path(context.Background(), start, end, 200)
 	// 		destiny := navigation.GetDirectionFromPath(path, 5)
 
 	// 		for _, step := range path {
@@ -339,7 +399,7 @@ var _ = Describe("Grid", func() {
 	// 		grid := navigation.NewGrid(10, 5)
 	// 		start := grid.GetTile(9, 4)
 	// 		end := grid.GetTile(0, 0)
-	// 		path, distance, found, _ := grid.Path(start, end, 200)
+	// 		path, distance, found, _, _ := grid.Path(context.Background(), start, end, 200)
 	// 		destiny := navigation.GetDirectionFromPath(path, 5)
 
 	// 		for _, step := range path {
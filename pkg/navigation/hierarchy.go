@@ -0,0 +1,492 @@
+package navigation
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+
+	"github.com/metalblueberry/halite-bot/pkg/astar"
+)
+
+// entranceWidth is the length of the border openings that get an entrance at each end instead of one in the middle
+const entranceWidth = 6
+
+// Hierarchy is the abstract graph of HPA*. The grid is split in square clusters and the openings between neighbor
+// clusters are entrances, linked inside each cluster by the cost of the paths between them. Long paths are planned
+// on the abstract graph and only the next segment is refined on the grid
+type Hierarchy struct {
+	ClusterSize   int
+	Columns, Rows int
+	grid          *Grid
+	// signatures are hashes of the blockers of every cluster, see Update
+	signatures []uint64
+	// borders are the entrances of every cluster with the next one to the right and below
+	borders [][2][]*entrance
+}
+
+// entrance is an opening between two neighbor clusters, inner is the side of the cluster that owns the border
+type entrance struct {
+	inner, outer *abstractNode
+}
+
+// abstractNode is a tile at the side of an entrance
+type abstractNode struct {
+	index   int
+	cluster int
+	edges   []abstractEdge
+}
+
+type abstractEdge struct {
+	to   *abstractNode
+	cost float64
+}
+
+// NewHierarchy splits the grid in clusters of the given size and builds the abstract graph
+func NewHierarchy(grid *Grid, clusterSize int) *Hierarchy {
+	h := &Hierarchy{
+		ClusterSize: clusterSize,
+		Columns:     (grid.Width + clusterSize - 1) / clusterSize,
+		Rows:        (grid.Height + clusterSize - 1) / clusterSize,
+		grid:        grid,
+	}
+	h.signatures = make([]uint64, h.Columns*h.Rows)
+	h.borders = make([][2][]*entrance, h.Columns*h.Rows)
+	for cluster := range h.signatures {
+		h.signatures[cluster] = h.signature(cluster)
+		h.buildBorder(cluster, 0)
+		h.buildBorder(cluster, 1)
+	}
+	for cluster := range h.signatures {
+		h.link(cluster)
+	}
+	return h
+}
+
+// Update moves the hierarchy to a new grid. Only the clusters where Blocked or Ship tiles changed, like destroyed
+// planets or moved ships, and their neighbors are built again unless the size of the grid changed.
+// It returns the number of rebuilt clusters
+func (h *Hierarchy) Update(grid *Grid) int {
+	if grid.Width != h.grid.Width || grid.Height != h.grid.Height {
+		*h = *NewHierarchy(grid, h.ClusterSize)
+		return len(h.signatures)
+	}
+	h.grid = grid
+	dirty := make(map[int]bool)
+	for cluster := range h.signatures {
+		signature := h.signature(cluster)
+		if signature == h.signatures[cluster] {
+			continue
+		}
+		h.signatures[cluster] = signature
+		x, y := cluster%h.Columns, cluster/h.Columns
+		h.buildBorder(cluster, 0)
+		h.buildBorder(cluster, 1)
+		dirty[cluster] = true
+		if x > 0 {
+			h.buildBorder(cluster-1, 0)
+			dirty[cluster-1] = true
+		}
+		if y > 0 {
+			h.buildBorder(cluster-h.Columns, 1)
+			dirty[cluster-h.Columns] = true
+		}
+		if x < h.Columns-1 {
+			dirty[cluster+1] = true
+		}
+		if y < h.Rows-1 {
+			dirty[cluster+h.Columns] = true
+		}
+	}
+	for cluster := range dirty {
+		h.link(cluster)
+	}
+	return len(dirty)
+}
+
+// Cluster returns the cluster that contains the tile
+func (h *Hierarchy) Cluster(tile *Tile) int {
+	return int(tile.Y)/h.ClusterSize*h.Columns + int(tile.X)/h.ClusterSize
+}
+
+// Entrances returns the tiles of the cluster that lead to its neighbors
+func (h *Hierarchy) Entrances(cluster int) []*Tile {
+	nodes := h.nodes(cluster)
+	tiles := make([]*Tile, len(nodes))
+	for i, node := range nodes {
+		tiles[i] = h.grid.Tiles[node.index]
+	}
+	return tiles
+}
+
+// bounds returns the first tile and the last tile, not included, of the cluster in both axes
+func (h *Hierarchy) bounds(cluster int) (minX, minY, maxX, maxY int) {
+	minX, minY = cluster%h.Columns*h.ClusterSize, cluster/h.Columns*h.ClusterSize
+	maxX, maxY = minX+h.ClusterSize, minY+h.ClusterSize
+	if maxX > h.grid.Width {
+		maxX = h.grid.Width
+	}
+	if maxY > h.grid.Height {
+		maxY = h.grid.Height
+	}
+	return minX, minY, maxX, maxY
+}
+
+func (h *Hierarchy) signature(cluster int) uint64 {
+	minX, minY, maxX, maxY := h.bounds(cluster)
+	hash := fnv.New64a()
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			if tile := h.grid.Tiles[y*h.grid.Width+x]; tile.obstacle() {
+				index := y*h.grid.Width + x
+				hash.Write([]byte{byte(index), byte(index >> 8), byte(index >> 16), byte(tile.Type >> 10)})
+			}
+		}
+	}
+	return hash.Sum64()
+}
+
+// buildBorder finds the entrances between the cluster and the next one to the right, side 0, or below, side 1
+func (h *Hierarchy) buildBorder(cluster, side int) {
+	h.borders[cluster][side] = nil
+	x, y := cluster%h.Columns, cluster/h.Columns
+	if side == 0 && x == h.Columns-1 || side == 1 && y == h.Rows-1 {
+		return
+	}
+	minX, minY, maxX, maxY := h.bounds(cluster)
+	neighbor := cluster + 1
+	length, inner, outer := maxY-minY, func(i int) int { return (minY+i)*h.grid.Width + maxX - 1 }, 1
+	if side == 1 {
+		neighbor = cluster + h.Columns
+		length, inner, outer = maxX-minX, func(i int) int { return (maxY-1)*h.grid.Width + minX + i }, h.grid.Width
+	}
+
+	open := func(i int) bool {
+		return i < length && h.grid.Tiles[inner(i)].Type != Blocked && h.grid.Tiles[inner(i)+outer].Type != Blocked
+	}
+	add := func(i int) {
+		e := &entrance{
+			inner: &abstractNode{index: inner(i), cluster: cluster},
+			outer: &abstractNode{index: inner(i) + outer, cluster: neighbor},
+		}
+		h.borders[cluster][side] = append(h.borders[cluster][side], e)
+	}
+	for start := 0; start < length; start++ {
+		if !open(start) {
+			continue
+		}
+		end := start
+		for open(end + 1) {
+			end++
+		}
+		if end-start+1 < entranceWidth {
+			add((start + end) / 2)
+		} else {
+			add(start)
+			add(end)
+		}
+		start = end
+	}
+}
+
+// nodes returns the abstract nodes inside the cluster from the borders with its four neighbors
+func (h *Hierarchy) nodes(cluster int) []*abstractNode {
+	nodes := []*abstractNode{}
+	for _, e := range h.borders[cluster][0] {
+		nodes = append(nodes, e.inner)
+	}
+	for _, e := range h.borders[cluster][1] {
+		nodes = append(nodes, e.inner)
+	}
+	if cluster%h.Columns > 0 {
+		for _, e := range h.borders[cluster-1][0] {
+			nodes = append(nodes, e.outer)
+		}
+	}
+	if cluster >= h.Columns {
+		for _, e := range h.borders[cluster-h.Columns][1] {
+			nodes = append(nodes, e.outer)
+		}
+	}
+	return nodes
+}
+
+// link sets the edges of the nodes of the cluster, to the other side of their entrance and to the nodes
+// of the same cluster that they reach without leaving it
+func (h *Hierarchy) link(cluster int) {
+	nodes := h.nodes(cluster)
+	weights, locals := h.clusterWeights(cluster), h.locals(cluster, nodes)
+	for i, node := range nodes {
+		node.edges = node.edges[:0]
+		costs := h.clusterCosts(weights, locals[i], false, locals)
+		for j, other := range nodes {
+			if other != node && !math.IsInf(costs[locals[j]], 1) {
+				node.edges = append(node.edges, abstractEdge{to: other, cost: costs[locals[j]]})
+			}
+		}
+	}
+	for _, node := range nodes {
+		node.edges = append(node.edges, abstractEdge{to: h.twin(node), cost: -1})
+	}
+}
+
+// twin returns the node at the other side of the entrance of the node
+func (h *Hierarchy) twin(node *abstractNode) *abstractNode {
+	for _, e := range h.entrances(node.cluster) {
+		switch node {
+		case e.inner:
+			return e.outer
+		case e.outer:
+			return e.inner
+		}
+	}
+	return nil
+}
+
+func (h *Hierarchy) entrances(cluster int) []*entrance {
+	entrances := append([]*entrance{}, h.borders[cluster][0]...)
+	entrances = append(entrances, h.borders[cluster][1]...)
+	if cluster%h.Columns > 0 {
+		entrances = append(entrances, h.borders[cluster-1][0]...)
+	}
+	if cluster >= h.Columns {
+		entrances = append(entrances, h.borders[cluster-h.Columns][1]...)
+	}
+	return entrances
+}
+
+// clusterWeights copies the weights of the tiles of the cluster indexed by local, Blocked tiles are +Inf
+func (h *Hierarchy) clusterWeights(cluster int) []float64 {
+	minX, minY, maxX, maxY := h.bounds(cluster)
+	weights := make([]float64, h.ClusterSize*h.ClusterSize)
+	for i := range weights {
+		weights[i] = math.Inf(1)
+	}
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			if tile := h.grid.Tiles[y*h.grid.Width+x]; tile.Type != Blocked {
+				weights[(y-minY)*h.ClusterSize+x-minX] = tile.weight()
+			}
+		}
+	}
+	return weights
+}
+
+// clusterCosts finds the cost of the paths from the tile to the targets without leaving the cluster, or from the targets
+// to the tile when reverse is set. Costs are the same as in Path and they are indexed by local, tiles and weights too
+func (h *Hierarchy) clusterCosts(weights []float64, source int, reverse bool, targets []int) []float64 {
+	size := h.ClusterSize
+	costs := make([]float64, len(weights))
+	for i := range costs {
+		costs[i] = math.Inf(1)
+	}
+	// pending marks the targets whose cost is not final yet
+	pending := make([]bool, len(weights))
+	left := 0
+	for _, target := range targets {
+		if !pending[target] {
+			pending[target] = true
+			left++
+		}
+	}
+
+	costs[source] = 0
+	open := distanceQueue{{index: source}}
+	for len(open) > 0 && left > 0 {
+		current := open.pop()
+		if current.distance > costs[current.index] {
+			continue
+		}
+		if pending[current.index] {
+			pending[current.index] = false
+			left--
+		}
+		x, y := current.index%size, current.index/size
+		for _, step := range neighborSteps {
+			nx, ny := x+step.dx, y+step.dy
+			if nx < 0 || nx >= size || ny < 0 || ny >= size {
+				continue
+			}
+			next := ny*size + nx
+			if math.IsInf(weights[next], 1) {
+				continue
+			}
+			weight := weights[next]
+			if reverse {
+				weight = weights[current.index]
+			}
+			if cost := current.distance + step.cost*weight; cost < costs[next] {
+				costs[next] = cost
+				open.push(distanceItem{index: next, distance: cost})
+			}
+		}
+	}
+	return costs
+}
+
+// local converts the index of a tile of the grid to the index inside its cluster
+func (h *Hierarchy) local(cluster, index int) int {
+	minX, minY, _, _ := h.bounds(cluster)
+	return (index/h.grid.Width-minY)*h.ClusterSize + index%h.grid.Width - minX
+}
+
+// locals converts the nodes of a cluster to their local indexes
+func (h *Hierarchy) locals(cluster int, nodes []*abstractNode) []int {
+	indexes := make([]int, len(nodes))
+	for i, node := range nodes {
+		indexes[i] = h.local(cluster, node.index)
+	}
+	return indexes
+}
+
+// abstractSearch is a search on the abstract graph with the start and the goal linked to the nodes of their clusters
+type abstractSearch struct {
+	h           *Hierarchy
+	start, goal *abstractNode
+	// toGoal are the costs from the nodes of the goal cluster to the goal
+	toGoal map[*abstractNode]float64
+}
+
+// abstractStep is the astar.Pather of the abstract graph
+type abstractStep struct {
+	node   *abstractNode
+	search *abstractSearch
+}
+
+func (s abstractStep) PathNeighbors() []astar.Pather {
+	neighbors := make([]astar.Pather, 0, len(s.node.edges)+1)
+	for _, edge := range s.node.edges {
+		if edge.to != nil {
+			neighbors = append(neighbors, abstractStep{node: edge.to, search: s.search})
+		}
+	}
+	if _, ok := s.search.toGoal[s.node]; ok {
+		neighbors = append(neighbors, abstractStep{node: s.search.goal, search: s.search})
+	}
+	return neighbors
+}
+
+func (s abstractStep) PathNeighborCost(to astar.Pather) float64 {
+	next := to.(abstractStep).node
+	if next == s.search.goal {
+		return s.search.toGoal[s.node]
+	}
+	for _, edge := range s.node.edges {
+		if edge.to != next {
+			continue
+		}
+		if edge.cost < 0 {
+			// The cost between the sides of an entrance is always up to date
+			from, to := s.search.h.grid.Tiles[s.node.index], s.search.h.grid.Tiles[next.index]
+			return from.PathNeighborCost(to)
+		}
+		return edge.cost
+	}
+	return math.Inf(1)
+}
+
+func (s abstractStep) PathEstimatedCost(to astar.Pather) float64 {
+	grid := s.search.h.grid
+	return grid.Tiles[s.node.index].gridDistance(grid.Tiles[to.(abstractStep).node.index])
+}
+
+// AbstractPath finds the entrances that the path between the tiles goes through, the path includes both ends
+func (h *Hierarchy) AbstractPath(ctx context.Context, from, to *Tile) (path []*Tile, distance float64, found bool, stats astar.Stats) {
+	fromCluster, toCluster := h.Cluster(from), h.Cluster(to)
+	search := &abstractSearch{
+		h:      h,
+		start:  &abstractNode{index: from.PathIndex(), cluster: fromCluster},
+		goal:   &abstractNode{index: to.PathIndex(), cluster: toCluster},
+		toGoal: make(map[*abstractNode]float64),
+	}
+
+	fromNodes, toNodes := h.nodes(fromCluster), h.nodes(toCluster)
+	fromLocals, toLocals := h.locals(fromCluster, fromNodes), h.locals(toCluster, toNodes)
+	goalLocal := h.local(toCluster, to.PathIndex())
+
+	fromTargets := fromLocals
+	if fromCluster == toCluster {
+		fromTargets = append(fromTargets, goalLocal)
+	}
+	fromCosts := h.clusterCosts(h.clusterWeights(fromCluster), h.local(fromCluster, from.PathIndex()), false, fromTargets)
+	for i, node := range fromNodes {
+		if cost := fromCosts[fromLocals[i]]; !math.IsInf(cost, 1) {
+			search.start.edges = append(search.start.edges, abstractEdge{to: node, cost: cost})
+		}
+	}
+	if fromCluster == toCluster && !math.IsInf(fromCosts[goalLocal], 1) {
+		search.toGoal[search.start] = fromCosts[goalLocal]
+	}
+	toCosts := h.clusterCosts(h.clusterWeights(toCluster), goalLocal, true, toLocals)
+	for i, node := range toNodes {
+		if cost := toCosts[toLocals[i]]; !math.IsInf(cost, 1) {
+			search.toGoal[node] = cost
+		}
+	}
+
+	start, goal := abstractStep{node: search.start, search: search}, abstractStep{node: search.goal, search: search}
+	steps, distance, found, _, stats := astar.PathContext(ctx, start, goal, -1)
+	if !found {
+		return nil, 0, false, stats
+	}
+	path = make([]*Tile, len(steps))
+	for i, step := range steps {
+		path[i] = h.grid.Tiles[step.(abstractStep).node.index]
+	}
+	return path, distance, true, stats
+}
+
+// Path plans the path between the tiles on the abstract graph and refines the next segment with Grid.Path.
+// The path is the refined segment followed by the rest of the entrances, the segment goes to the first entrance
+// at least a cluster away. The waypoints from the end of the segment on are skipped while they are in line of sight of
+// the previous one kept, so straight routes keep few waypoints. When the tiles are not connected in the abstract graph, it falls back to Grid.Path
+func (h *Hierarchy) Path(ctx context.Context, from, to *Tile, iterations int) (path []*Tile, distance float64, found bool, bestPath []*Tile, stats astar.Stats) {
+	return h.PathWeighted(ctx, nil, from, to, iterations)
+}
+
+// PathWeighted works like Path but the segment is refined with Grid.PathWeighted. The abstract graph always uses
+// the Weighter of the grid
+func (h *Hierarchy) PathWeighted(ctx context.Context, weighter Weighter, from, to *Tile, iterations int) (path []*Tile, distance float64, found bool, bestPath []*Tile, stats astar.Stats) {
+	waypoints, distance, found, abstractStats := h.AbstractPath(ctx, from, to)
+	if !found {
+		path, distance, found, bestPath, stats = h.grid.PathWeighted(ctx, weighter, from, to, iterations)
+		stats.Expanded += abstractStats.Expanded
+		stats.Elapsed += abstractStats.Elapsed
+		return path, distance, found, bestPath, stats
+	}
+
+	next := len(waypoints) - 1
+	for i, waypoint := range waypoints {
+		if waypoint.gridDistance(from) >= float64(h.ClusterSize) {
+			next = i
+			break
+		}
+	}
+	segment, _, found, bestPath, stats := h.grid.PathWeighted(ctx, weighter, from, waypoints[next], iterations)
+	stats.Expanded += abstractStats.Expanded
+	stats.Elapsed += abstractStats.Elapsed
+	if !found {
+		return nil, distance, false, bestPath, stats
+	}
+	if len(segment) > 1 {
+		path = append(segment[:len(segment)-1], h.smooth(weighter, segment[len(segment)-2], waypoints[next:])...)
+	} else {
+		path = append(segment, h.smooth(weighter, from, waypoints[next+1:])...)
+	}
+	return path, distance, true, path, stats
+}
+
+// smooth drops the waypoints that can be skipped flying in straight line from the previous one kept
+func (h *Hierarchy) smooth(weighter Weighter, from *Tile, waypoints []*Tile) []*Tile {
+	if weighter == nil {
+		weighter = h.grid.Weighter
+	}
+	smooth := make([]*Tile, 0, len(waypoints))
+	last := from
+	for i, waypoint := range waypoints {
+		if i == len(waypoints)-1 || !h.grid.lineOfSight(weighter, last, waypoints[i+1]) {
+			smooth = append(smooth, waypoint)
+			last = waypoint
+		}
+	}
+	return smooth
+}
@@ -0,0 +1,104 @@
+package navigation_test
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+var _ = Describe("Hierarchy", func() {
+	It("Should put an entrance at each end of wide openings", func() {
+		grid := navigation.NewGrid(32, 16)
+
+		hierarchy := navigation.NewHierarchy(grid, 16)
+
+		Expect(hierarchy.Columns).To(Equal(2))
+		Expect(hierarchy.Rows).To(Equal(1))
+		Expect(hierarchy.Entrances(0)).To(ConsistOf(grid.GetTile(15, 0), grid.GetTile(15, 15)))
+		Expect(hierarchy.Entrances(1)).To(ConsistOf(grid.GetTile(16, 0), grid.GetTile(16, 15)))
+	})
+	It("Should put an entrance in the middle of narrow openings", func() {
+		grid := navigation.NewGrid(32, 16)
+		for y := 0.0; y < 16; y++ {
+			if y < 6 || y > 8 {
+				grid.GetTile(16, y).Type = navigation.Blocked
+			}
+		}
+
+		hierarchy := navigation.NewHierarchy(grid, 16)
+
+		Expect(hierarchy.Entrances(0)).To(ConsistOf(grid.GetTile(15, 7)))
+	})
+	It("Should find abstract paths a bit longer than the optimal ones", func() {
+		grid := obstacleGrid(120, 80)
+		hierarchy := navigation.NewHierarchy(grid, 16)
+		pairs := [][4]float64{{0, 0, 119, 79}, {0, 40, 119, 40}, {60, 0, 60, 79}, {10, 70, 110, 5}}
+		for _, pair := range pairs {
+			from, to := grid.GetTile(pair[0], pair[1]), grid.GetTile(pair[2], pair[3])
+
+			_, expected, _, _, _ := grid.Path(context.Background(), from, to, -1)
+			path, distance, found, _ := hierarchy.AbstractPath(context.Background(), from, to)
+
+			Expect(found).To(BeTrue())
+			Expect(path[0]).To(Equal(from))
+			Expect(path[len(path)-1]).To(Equal(to))
+			Expect(distance).To(BeNumerically(">=", expected-1e-9))
+			Expect(distance).To(BeNumerically("<", expected*1.2))
+		}
+	})
+	It("Should refine the next segment on the grid", func() {
+		grid := obstacleGrid(120, 80)
+		hierarchy := navigation.NewHierarchy(grid, 16)
+		from, to := grid.GetTile(0, 0), grid.GetTile(119, 79)
+
+		path, _, found, _, _ := hierarchy.Path(context.Background(), from, to, 300)
+
+		Expect(found).To(BeTrue())
+		Expect(path[0]).To(Equal(from))
+		Expect(path[len(path)-1]).To(Equal(to))
+		refined := 1
+		for refined < len(path) && math.Abs(path[refined].X-path[refined-1].X) <= 1 && math.Abs(path[refined].Y-path[refined-1].Y) <= 1 {
+			refined++
+		}
+		Expect(path[refined-1].DistanceTo(from)).To(BeNumerically(">=", 16))
+	})
+	It("Should find long paths that run out of iterations on the grid", func() {
+		grid := obstacleGrid(384, 256)
+		hierarchy := navigation.NewHierarchy(grid, 16)
+		from, to := grid.GetTile(0, 0), grid.GetTile(383, 255)
+
+		_, _, gridFound, _, _ := grid.Path(context.Background(), from, to, 300)
+		_, _, found, _, _ := hierarchy.Path(context.Background(), from, to, 300)
+
+		Expect(gridFound).To(BeFalse())
+		Expect(found).To(BeTrue())
+	})
+	It("Should only rebuild the clusters around the changes", func() {
+		grid := navigation.NewGrid(64, 64)
+		hierarchy := navigation.NewHierarchy(grid, 16)
+
+		Expect(hierarchy.Update(navigation.NewGrid(64, 64))).To(Equal(0))
+
+		changed := navigation.NewGrid(64, 64)
+		changed.PaintShip(40, 40, 0)
+		Expect(hierarchy.Update(changed)).To(Equal(5))
+	})
+	It("Should open the entrances of destroyed planets", func() {
+		grid := navigation.NewGrid(64, 32)
+		grid.PaintPlanet(32, 16, 6)
+		hierarchy := navigation.NewHierarchy(grid, 16)
+		_, blocked, _, _ := hierarchy.AbstractPath(context.Background(), grid.GetTile(20, 16), grid.GetTile(44, 16))
+
+		free := navigation.NewGrid(64, 32)
+		hierarchy.Update(free)
+		_, distance, found, _ := hierarchy.AbstractPath(context.Background(), free.GetTile(20, 16), free.GetTile(44, 16))
+
+		Expect(found).To(BeTrue())
+		Expect(distance).To(BeNumerically("<", blocked))
+		Expect(distance).To(BeNumerically("~", 24, 1e-9))
+	})
+})
@@ -0,0 +1,141 @@
+package navigation
+
+import (
+	"math"
+)
+
+// ThreatWeight is the extra cost of crossing a tile where a full health enemy can shoot next turn.
+// It matches the cost of the old ShotRange tiles so one enemy weights the same as before
+const ThreatWeight = float64(ShotRange)
+
+// Influence measures how much fire power each side can bring to every tile of the grid next turn.
+// Values are the sum of the strength of the ships that reach the tile, a full health ship ready to shoot is 1.
+// The layer has a value per tile of the grid, positions and distances are in map units like in the Grid
+type Influence struct {
+	Width, Height int
+	// Scale is the number of tiles per unit of the map, the same as the Scale of the grid
+	Scale    float64
+	Enemy    []float64
+	Friendly []float64
+	// revision counts the ships added, see Grid.Invalidate
+	revision int
+}
+
+// NewInfluence creates an empty influence layer with the size of the grid
+func NewInfluence(width, height int) *Influence {
+	return &Influence{
+		Width:    width,
+		Height:   height,
+		Scale:    1,
+		Enemy:    make([]float64, width*height),
+		Friendly: make([]float64, width*height),
+	}
+}
+
+// AddShip spreads the strength of a ship around its position. Tiles closer than fire are at full strength,
+// the ship can shoot them without moving, and the strength fades linearly until reach
+func (in *Influence) AddShip(X, Y, strength, fire, reach float64, enemy bool) {
+	if strength <= 0 {
+		return
+	}
+	in.revision++
+	layer := in.Friendly
+	if enemy {
+		layer = in.Enemy
+	}
+	X, Y, fire, reach = X*in.Scale, Y*in.Scale, fire*in.Scale, reach*in.Scale
+	minX, maxX := math.Max(math.Floor(X-reach), 0), math.Min(math.Ceil(X+reach), float64(in.Width-1))
+	minY, maxY := math.Max(math.Floor(Y-reach), 0), math.Min(math.Ceil(Y+reach), float64(in.Height-1))
+	for j := minY; j <= maxY; j++ {
+		for i := minX; i <= maxX; i++ {
+			distance := math.Hypot(X-i, Y-j)
+			if distance > reach {
+				continue
+			}
+			value := strength
+			if distance > fire {
+				value *= (reach - distance) / (reach - fire)
+			}
+			layer[int(j)*in.Width+int(i)] += value
+		}
+	}
+}
+
+func (in *Influence) index(x, y float64) (int, bool) {
+	x, y = math.Round(x*in.Scale), math.Round(y*in.Scale)
+	if x < 0 || x >= float64(in.Width) || y < 0 || y >= float64(in.Height) {
+		return 0, false
+	}
+	return int(y)*in.Width + int(x), true
+}
+
+// EnemyAt returns the enemy influence at the tile that contains the position, 0 outside the grid
+func (in *Influence) EnemyAt(x, y float64) float64 {
+	index, ok := in.index(x, y)
+	if !ok {
+		return 0
+	}
+	return in.Enemy[index]
+}
+
+// FriendlyAt returns the friendly influence at the tile that contains the position, 0 outside the grid
+func (in *Influence) FriendlyAt(x, y float64) float64 {
+	index, ok := in.index(x, y)
+	if !ok {
+		return 0
+	}
+	return in.Friendly[index]
+}
+
+// Around returns the highest enemy and friendly influence of the tiles within radius
+func (in *Influence) Around(X, Y, radius float64) (enemy, friendly float64) {
+	X, Y, radius = X*in.Scale, Y*in.Scale, radius*in.Scale
+	for j := math.Max(math.Ceil(Y-radius), 0); j <= Y+radius && j < float64(in.Height); j++ {
+		for i := math.Max(math.Ceil(X-radius), 0); i <= X+radius && i < float64(in.Width); i++ {
+			if math.Hypot(X-i, Y-j) > radius {
+				continue
+			}
+			index := int(j)*in.Width + int(i)
+			enemy = math.Max(enemy, in.Enemy[index])
+			friendly = math.Max(friendly, in.Friendly[index])
+		}
+	}
+	return enemy, friendly
+}
+
+// Contested reports if the enemies can bring at least as much fire power as us to the area
+func (in *Influence) Contested(X, Y, radius float64) bool {
+	enemy, friendly := in.Around(X, Y, radius)
+	return enemy > 0 && enemy >= friendly
+}
+
+// HeatPoint is a sample of the influence layer, it implements Circle so it can be drawn in the debug canvas
+type HeatPoint struct {
+	X, Y, R         float64
+	Enemy, Friendly float64
+}
+
+func (p HeatPoint) Circle() (x, y, radius float64) {
+	return p.X, p.Y, p.R
+}
+
+// Heatmap samples the layer every step tiles and returns the points with any influence
+func (in *Influence) Heatmap(step int) []HeatPoint {
+	points := []HeatPoint{}
+	for y := 0; y < in.Height; y += step {
+		for x := 0; x < in.Width; x += step {
+			index := y*in.Width + x
+			if in.Enemy[index] == 0 && in.Friendly[index] == 0 {
+				continue
+			}
+			points = append(points, HeatPoint{
+				X:        float64(x) / in.Scale,
+				Y:        float64(y) / in.Scale,
+				R:        float64(step) / 2 / in.Scale,
+				Enemy:    in.Enemy[index],
+				Friendly: in.Friendly[index],
+			})
+		}
+	}
+	return points
+}
@@ -0,0 +1,68 @@
+package navigation_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+var _ = Describe("Influence", func() {
+	var influence *navigation.Influence
+	BeforeEach(func() {
+		influence = navigation.NewInfluence(40, 20)
+	})
+	It("Should have full strength within fire range and fade until reach", func() {
+		influence.AddShip(10, 10, 1, 5, 10, true)
+
+		Expect(influence.EnemyAt(10, 10)).To(Equal(1.0))
+		Expect(influence.EnemyAt(15, 10)).To(Equal(1.0))
+		Expect(influence.EnemyAt(17, 10)).To(BeNumerically("~", 0.6, 1e-9))
+		Expect(influence.EnemyAt(21, 10)).To(Equal(0.0))
+		Expect(influence.FriendlyAt(10, 10)).To(Equal(0.0))
+	})
+	It("Should add the strength of several ships", func() {
+		influence.AddShip(10, 10, 1, 5, 10, false)
+		influence.AddShip(12, 10, 0.5, 5, 10, false)
+
+		Expect(influence.FriendlyAt(11, 10)).To(Equal(1.5))
+	})
+	It("Should ignore positions outside the grid", func() {
+		influence.AddShip(0, 0, 1, 5, 10, true)
+
+		Expect(influence.EnemyAt(-1, 0)).To(Equal(0.0))
+		Expect(influence.EnemyAt(0, 0)).To(Equal(1.0))
+	})
+	It("Should report contested areas when the enemy is at least as strong", func() {
+		influence.AddShip(10, 10, 1, 5, 10, true)
+		Expect(influence.Contested(20, 10, 5)).To(BeTrue())
+
+		influence.AddShip(30, 10, 1, 5, 10, false)
+		influence.AddShip(30, 10, 1, 5, 10, false)
+		Expect(influence.Contested(20, 10, 5)).To(BeFalse())
+		Expect(influence.Contested(35, 10, 2)).To(BeFalse())
+	})
+	It("Should sample only the tiles with influence in the heatmap", func() {
+		influence.AddShip(10, 10, 1, 1, 2, true)
+
+		points := influence.Heatmap(2)
+		Expect(points).NotTo(BeEmpty())
+		for _, point := range points {
+			Expect(point.Enemy).To(BeNumerically(">", 0))
+			Expect(point.R).To(Equal(1.0))
+		}
+	})
+	It("Should make paths go around the enemy fire", func() {
+		grid := navigation.NewGrid(20, 9)
+		grid.Influence.AddShip(10, 4, 1, 3, 4, true)
+
+		path, _, found, _, _ := grid.Path(context.Background(), grid.GetTile(1, 4), grid.GetTile(18, 4), 2000)
+
+		Expect(found).To(BeTrue())
+		for _, step := range path {
+			Expect(grid.Influence.EnemyAt(step.Position())).To(BeNumerically("<", 1))
+		}
+	})
+})
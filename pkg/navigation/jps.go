@@ -0,0 +1,275 @@
+package navigation
+
+import (
+	"math"
+
+	"github.com/metalblueberry/halite-bot/pkg/astar"
+)
+
+// Pathfinder is the algorithm used by Grid.Path
+type Pathfinder int
+
+const (
+	// AStar expands all the neighbors of every tile
+	AStar Pathfinder = iota
+	// JumpPoint skips the tiles of the regions with uniform cost, see jumpPoints
+	JumpPoint
+	// ThetaStar finds any-angle paths made of straight segments with Lazy Theta*, see anyAngle
+	ThetaStar
+)
+
+func (p Pathfinder) String() string {
+	return [...]string{"astar", "jps", "theta"}[p]
+}
+
+// jumpPoints is a weighted Jump Point Search. Empty tiles without enemy influence have all the same cost, inside those
+// regions the search jumps in straight lines like the classic JPS. Tiles with a different cost, and their neighbors,
+// are jump points that expand all their neighbors, so the paths have the same cost as the ones found by A*
+type jumpPoints struct {
+	grid     *Grid
+	weighter Weighter
+	schedule *schedule
+	*jumpTable
+}
+
+// jumpTable is what the jumps test at every step, it only depends on the grid and the weighter
+type jumpTable struct {
+	// interior tells for each tile if it and all its neighbors have the minimum cost
+	interior []bool
+	// stops are, for each straight direction of neighborSteps and each tile, the steps to the first tile that is not
+	// interior. They are negative when that tile is Blocked or out of the grid, so the jump finds nothing there
+	stops [4][]int32
+}
+
+// newJumpPoints prepares a search with the schedule of the reservations, nil without them. The weighters of the grid
+// keep their jump table until the grid changes, other weighters build it again for every search
+func newJumpPoints(grid *Grid, weighter Weighter, schedule *schedule) jumpPoints {
+	return jumpPoints{grid: grid, weighter: weighter, schedule: schedule, jumpTable: grid.jumpTable(weighter)}
+}
+
+// jumpTable returns the table of the weighter, building it again only when the version of the grid changes
+func (g *Grid) jumpTable(weighter Weighter) *jumpTable {
+	l, ok := weighter.(*layered)
+	if !ok || l.grid != g {
+		return g.newJumpTable(weighter)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if version := g.version(); l.jumps == nil || l.version != version {
+		l.jumps, l.version = g.newJumpTable(weighter), version
+	}
+	return l.jumps
+}
+
+// newJumpTable marks the uniform tiles whose neighbors are also uniform and measures the straight runs of them.
+// The uniform tiles have the minimum weight, 1
+func (g *Grid) newJumpTable(weighter Weighter) *jumpTable {
+	uniform := make([]bool, len(g.Tiles))
+	for i, tile := range g.Tiles {
+		uniform[i] = tile.Type != Blocked && tile.weightWith(weighter) <= 1
+	}
+	table := &jumpTable{interior: make([]bool, len(g.Tiles))}
+	for y := 0; y < g.Height; y++ {
+	tiles:
+		for x := 0; x < g.Width; x++ {
+			if !uniform[y*g.Width+x] {
+				continue
+			}
+			for _, step := range neighborSteps {
+				nx, ny := x+step.dx, y+step.dy
+				if nx < 0 || nx >= g.Width || ny < 0 || ny >= g.Height {
+					continue
+				}
+				if !uniform[ny*g.Width+nx] {
+					continue tiles
+				}
+			}
+			table.interior[y*g.Width+x] = true
+		}
+	}
+
+	// The tiles are visited against the direction, so the next tile is measured before the current one
+	for direction, step := range neighborSteps[:4] {
+		stops := make([]int32, len(g.Tiles))
+		for j := 0; j < g.Height; j++ {
+			y := j
+			if step.dy > 0 {
+				y = g.Height - 1 - j
+			}
+			for i := 0; i < g.Width; i++ {
+				x := i
+				if step.dx > 0 {
+					x = g.Width - 1 - i
+				}
+				nx, ny := x+step.dx, y+step.dy
+				next := ny*g.Width + nx
+				switch {
+				case nx < 0 || nx >= g.Width || ny < 0 || ny >= g.Height || g.Tiles[next].Type == Blocked:
+					stops[y*g.Width+x] = -1
+				case !table.interior[next]:
+					stops[y*g.Width+x] = 1
+				case stops[next] > 0:
+					stops[y*g.Width+x] = stops[next] + 1
+				default:
+					stops[y*g.Width+x] = stops[next] - 1
+				}
+			}
+		}
+		table.stops[direction] = stops
+	}
+	return table
+}
+
+// Expand implements astar.Expander. With reservations the jumps stop before the tiles reserved at the turn they get
+// there, those stops and the tiles reached waiting expand all their neighbors like the start
+func (j jumpPoints) Expand(current, parent, goal astar.Pather, cost, parentCost float64, successors []astar.Successor) []astar.Successor {
+	tile, target := tileOf(current), tileOf(goal)
+	if tile.Type == Blocked {
+		return successors
+	}
+	x, y := int(tile.X), int(tile.Y)
+
+	if parent != nil && j.interior[y*j.grid.Width+x] {
+		from := tileOf(parent)
+		dx, dy := sign(tile.X-from.X), sign(tile.Y-from.Y)
+		if (dx != 0 || dy != 0) && !j.stopped(tile, dx, dy, cost) {
+			// Natural neighbors, the pruned ones are reached with the same cost through other tiles
+			successors = j.appendJump(successors, x, y, dx, dy, target, cost)
+			if dx != 0 && dy != 0 {
+				successors = j.appendJump(successors, x, y, dx, 0, target, cost)
+				successors = j.appendJump(successors, x, y, 0, dy, target, cost)
+			}
+			return j.schedule.appendWait(successors, current, cost)
+		}
+	}
+
+	for _, step := range neighborSteps {
+		successors = j.appendJump(successors, x, y, step.dx, step.dy, target, cost)
+	}
+	return j.schedule.appendWait(successors, current, cost)
+}
+
+func (j jumpPoints) appendJump(successors []astar.Successor, x, y, dx, dy int, goal *Tile, cost float64) []astar.Successor {
+	if next, jumpCost := j.jump(x, y, dx, dy, goal, cost); next != nil {
+		successors = append(successors, astar.Successor{Node: next, Cost: jumpCost})
+	}
+	return successors
+}
+
+// stopped tells if the jump that got to the tile with the cost stopped there because the next tile is reserved
+func (j jumpPoints) stopped(tile *Tile, dx, dy int, cost float64) bool {
+	nx, ny := int(tile.X)+dx, int(tile.Y)+dy
+	if j.schedule == nil || nx < 0 || nx >= j.grid.Width || ny < 0 || ny >= j.grid.Height {
+		return false
+	}
+	next := j.grid.Tiles[ny*j.grid.Width+nx]
+	return j.schedule.reserved(next, cost+tile.gridDistance(next)*next.weightWith(j.weighter))
+}
+
+// jump moves from x, y, reached with the cost, in the direction until it finds a jump point and returns it with
+// the cost to get there from x, y
+func (j jumpPoints) jump(x, y, dx, dy int, goal *Tile, cost float64) (astar.Pather, float64) {
+	step := 1.0
+	if dx != 0 && dy != 0 {
+		step = math.Sqrt2
+	}
+	// The tiles crossed before the jump point are interior, so they have the minimum weight
+	crossed := 0.0
+	for {
+		x, y = x+dx, y+dy
+		if x < 0 || x >= j.grid.Width || y < 0 || y >= j.grid.Height {
+			return nil, 0
+		}
+		index := y*j.grid.Width + x
+		next := j.grid.Tiles[index]
+		if next.Type == Blocked {
+			return nil, 0
+		}
+		if j.schedule != nil && j.schedule.reserved(next, cost+step*(crossed+next.weightWith(j.weighter))) {
+			if crossed == 0 {
+				return nil, 0
+			}
+			// The tile before the reservation is a stop, the search waits there or goes around it
+			stop := j.grid.Tiles[index-dy*j.grid.Width-dx]
+			return j.schedule.node(stop, cost+step*crossed), step * crossed
+		}
+		if next == goal || !j.interior[index] {
+			nextCost := step * (crossed + next.weightWith(j.weighter))
+			return j.schedule.node(next, cost+nextCost), nextCost
+		}
+		// The interior tile has weight 1
+		crossed++
+		if dx != 0 && dy != 0 && (j.reaches(x, y, dx, 0, goal, cost+step*crossed) || j.reaches(x, y, 0, dy, goal, cost+step*crossed)) {
+			return j.schedule.node(next, cost+step*crossed), step * crossed
+		}
+	}
+}
+
+// reaches tells if a straight jump from x, y, reached with the cost, finds a jump point
+func (j jumpPoints) reaches(x, y, dx, dy int, goal *Tile, cost float64) bool {
+	stop := int(j.stops[straight(dx, dy)][y*j.grid.Width+x])
+	if stop > 0 {
+		return true
+	}
+	// The jump ends in a dead end, unless it crosses the goal or stops before a reservation
+	gx, gy := int(goal.X)-x, int(goal.Y)-y
+	if dx == 0 && gx == 0 && gy*dy > 0 && gy*dy < -stop || dy == 0 && gy == 0 && gx*dx > 0 && gx*dx < -stop {
+		return true
+	}
+	return j.stopsBefore(x, y, dx, dy, -stop-1, cost)
+}
+
+// stopsBefore tells if a straight jump from x, y, reached with the cost, across the interior tiles finds a tile
+// reserved after the first one. The interior tiles have weight 1, so they cost 1 each
+func (j jumpPoints) stopsBefore(x, y, dx, dy, interior int, cost float64) bool {
+	for k := 1; k <= interior && j.schedule.timed(cost+float64(k)); k++ {
+		if j.schedule.reserved(j.grid.Tiles[(y+k*dy)*j.grid.Width+x+k*dx], cost+float64(k)) {
+			return k > 1
+		}
+	}
+	return false
+}
+
+// straight is the index in neighborSteps of a straight step
+func straight(dx, dy int) int {
+	switch {
+	case dy < 0:
+		return 0
+	case dy > 0:
+		return 1
+	case dx < 0:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// interpolate adds the tiles between the jump points of a path, the waits keep the tile repeated
+func (j jumpPoints) interpolate(path []astar.Pather) []*Tile {
+	tiles := make([]*Tile, 0, len(path))
+	for i, step := range path {
+		tile := tileOf(step)
+		if i > 0 {
+			previous := tiles[len(tiles)-1]
+			dx, dy := sign(tile.X-previous.X), sign(tile.Y-previous.Y)
+			x, y := int(previous.X)+dx, int(previous.Y)+dy
+			for x != int(tile.X) || y != int(tile.Y) {
+				tiles = append(tiles, j.grid.Tiles[y*j.grid.Width+x])
+				x, y = x+dx, y+dy
+			}
+		}
+		tiles = append(tiles, tile)
+	}
+	return tiles
+}
+
+func sign(value float64) int {
+	switch {
+	case value > 0:
+		return 1
+	case value < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,122 @@
+package navigation_test
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+// expectSameCost checks that the jump point search finds a path as cheap as A* and that the path has no gaps
+func expectSameCost(grid *navigation.Grid, from, to *navigation.Tile) {
+	grid.Pathfinder = navigation.AStar
+	_, expected, expectedFound, _, _ := grid.Path(context.Background(), from, to, -1)
+
+	grid.Pathfinder = navigation.JumpPoint
+	path, distance, found, _, _ := grid.Path(context.Background(), from, to, -1)
+
+	Expect(found).To(Equal(expectedFound))
+	if !found {
+		return
+	}
+	Expect(distance).To(BeNumerically("~", expected, 1e-9))
+
+	Expect(path[0]).To(Equal(from))
+	Expect(path[len(path)-1]).To(Equal(to))
+	cost := 0.0
+	for i := 1; i < len(path); i++ {
+		Expect(math.Abs(path[i].X - path[i-1].X)).To(BeNumerically("<=", 1))
+		Expect(math.Abs(path[i].Y - path[i-1].Y)).To(BeNumerically("<=", 1))
+		cost += path[i-1].PathNeighborCost(path[i])
+	}
+	Expect(cost).To(BeNumerically("~", distance, 1e-9))
+}
+
+var _ = Describe("Jump Point Search", func() {
+	It("Should find the same cost in a clear path", func() {
+		grid := navigation.NewGrid(10, 3)
+		expectSameCost(grid, grid.GetTile(0, 1), grid.GetTile(9, 1))
+	})
+	It("Should find the same cost in a long clear path", func() {
+		grid := navigation.NewGrid(20, 3)
+		expectSameCost(grid, grid.GetTile(0, 1), grid.GetTile(19, 1))
+	})
+	It("Should find the same cost around obstacles", func() {
+		grid := navigation.NewGrid(11, 7)
+		grid.Paint(5, 4, 3, navigation.Blocked)
+		grid.Paint(5, 4, 4, navigation.SafeMargin)
+		expectSameCost(grid, grid.GetTile(0, 3), grid.GetTile(10, 3))
+	})
+	It("Should find the same cost around ships", func() {
+		grid := navigation.NewGrid(19, 15)
+		grid.Paint(4, 7, 5.0, navigation.ShotRange)
+		grid.Paint(4, 7, 1.5, navigation.Ship)
+		grid.Paint(14, 7, 5.0, navigation.ShotRange)
+		grid.Paint(14, 7, 1.5, navigation.Ship)
+		expectSameCost(grid, grid.GetTile(9, 0), grid.GetTile(9, 14))
+	})
+	It("Should find the same cost between painted ships", func() {
+		grid := navigation.NewGrid(19, 15)
+		grid.PaintShip(4, 7, 5)
+		grid.PaintShip(14, 7, 5)
+		expectSameCost(grid, grid.GetTile(9, 0), grid.GetTile(9, 14))
+	})
+	It("Should find the same cost with planets and enemy influence", func() {
+		grid := obstacleGrid(120, 80)
+		pairs := [][4]float64{{0, 0, 119, 79}, {0, 40, 119, 40}, {60, 0, 60, 79}, {10, 70, 110, 5}, {5, 5, 7, 60}}
+		for _, pair := range pairs {
+			expectSameCost(grid, grid.GetTile(pair[0], pair[1]), grid.GetTile(pair[2], pair[3]))
+		}
+	})
+	It("Should not find paths to unreachable tiles", func() {
+		grid := navigation.NewGrid(10, 10)
+		grid.Paint(9, 9, 2, navigation.Blocked)
+		grid.GetTile(9, 9).Type = navigation.Empty
+		expectSameCost(grid, grid.GetTile(0, 0), grid.GetTile(9, 9))
+	})
+	It("Should see the tiles painted after a search", func() {
+		grid := navigation.NewGrid(40, 20)
+		from, to := grid.GetTile(0, 10), grid.GetTile(39, 10)
+		expectSameCost(grid, from, to)
+
+		grid.PaintPlanet(20, 10, 5)
+		grid.Influence.AddShip(30, 4, 1, 2, 4, true)
+		expectSameCost(grid, from, to)
+	})
+	It("Should see the layers changed after Invalidate", func() {
+		grid := navigation.NewGrid(40, 20)
+		from, to := grid.GetTile(0, 10), grid.GetTile(39, 10)
+		expectSameCost(grid, from, to)
+
+		for y := 5; y < 15; y++ {
+			grid.Layer(navigation.ShipLayer)[y*grid.Width+20] = 1
+		}
+		grid.Invalidate()
+		expectSameCost(grid, from, to)
+	})
+	It("Should expand fewer tiles than A* in open space", func() {
+		grid := navigation.NewGrid(100, 100)
+		from, to := grid.GetTile(3, 5), grid.GetTile(90, 70)
+
+		_, _, _, _, astarStats := grid.Path(context.Background(), from, to, -1)
+		grid.Pathfinder = navigation.JumpPoint
+		_, _, _, _, jpsStats := grid.Path(context.Background(), from, to, -1)
+
+		Expect(jpsStats.Expanded).To(BeNumerically("<", astarStats.Expanded))
+	})
+	It("Should return a partial path without gaps when it runs out of iterations", func() {
+		grid := obstacleGrid(120, 80)
+		grid.Pathfinder = navigation.JumpPoint
+
+		_, _, found, bestPath, _ := grid.Path(context.Background(), grid.GetTile(0, 0), grid.GetTile(119, 79), 5)
+
+		Expect(found).To(BeFalse())
+		for i := 1; i < len(bestPath); i++ {
+			Expect(math.Abs(bestPath[i].X - bestPath[i-1].X)).To(BeNumerically("<=", 1))
+			Expect(math.Abs(bestPath[i].Y - bestPath[i-1].Y)).To(BeNumerically("<=", 1))
+		}
+	})
+})
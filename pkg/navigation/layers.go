@@ -0,0 +1,144 @@
+package navigation
+
+import (
+	"math"
+	"sync"
+)
+
+// Layer is one of the cost layers of the grid. Every layer has a value per tile and they never overwrite each other,
+// a Weighter combines them into the weight of the tiles
+type Layer int
+
+const (
+	// PlanetLayer is 1 on the tiles covered by a planet
+	PlanetLayer Layer = iota
+	// SafeMarginLayer is 1 on the tiles around the planets
+	SafeMarginLayer
+	// ShipLayer is 1 on the tiles covered by a ship
+	ShipLayer
+	// ShotRangeLayer counts the ships painted with a shot range that covers the tile, up to maxShotRanges
+	ShotRangeLayer
+	// EnemyFireLayer is the fire power that the enemies can bring to the tile next turn, it is Influence.Enemy
+	EnemyFireLayer
+	// FriendlyTrafficLayer is the fire power of our ships around the tile, it is Influence.Friendly
+	FriendlyTrafficLayer
+	// ReservationLayer is 1 on the tiles reserved by a pilot at any turn and on the Walked tiles. The searches check
+	// the reservations at the turn they get to the tiles, so the DefaultWeights ignore it
+	ReservationLayer
+	// LayerCount is the number of layers
+	LayerCount
+)
+
+func (l Layer) String() string {
+	return [...]string{"planets", "safe margins", "ships", "shot ranges", "enemy fire", "friendly traffic", "reservations"}[l]
+}
+
+// maxShotRanges is the most shot ranges counted on a tile, the types stop at ShotRange3 too
+const maxShotRanges = 3
+
+// layerOf is the layer that records every TileType painted on the grid
+var layerOf = map[TileType]Layer{
+	Blocked:    PlanetLayer,
+	SafeMargin: SafeMarginLayer,
+	Ship:       ShipLayer,
+	ShotRange:  ShotRangeLayer,
+	Walked:     ReservationLayer,
+}
+
+// Weights are the factors of the layers, the weight of a tile is 1 plus the value of every layer times its factor.
+// Factors must not be negative, the heuristic of the searches relies on weights of at least 1
+type Weights [LayerCount]float64
+
+// DefaultWeights are the weights used by the grids unless they are given other Weighter, they match the cost of
+// the tile types but the reservations
+var DefaultWeights = Weights{
+	PlanetLayer:          float64(Blocked),
+	SafeMarginLayer:      float64(SafeMargin),
+	ShipLayer:            float64(Ship),
+	ShotRangeLayer:       float64(ShotRange),
+	EnemyFireLayer:       ThreatWeight,
+	FriendlyTrafficLayer: 0,
+	ReservationLayer:     0,
+}
+
+// With returns a copy of the weights with the factor of the layer changed
+func (w Weights) With(layer Layer, factor float64) Weights {
+	w[layer] = factor
+	return w
+}
+
+// Layer returns the values of the layer indexed like Grid.Tiles, changes are seen by the searches once Invalidate
+// is called
+func (g *Grid) Layer(layer Layer) []float64 {
+	return g.layers[layer]
+}
+
+// Invalidate drops the data that the grid computes from the layers and the types of the tiles, it must be called
+// after changing them without the Paint methods, Reservations or Influence
+func (g *Grid) Invalidate() {
+	g.revision++
+}
+
+// version changes every time the layers or the types of the tiles change
+func (g *Grid) version() int {
+	return g.revision + g.Influence.revision
+}
+
+// Weighting returns the Weighter that combines the layers of the grid with the weights
+func (g *Grid) Weighting(weights Weights) Weighter {
+	weighter := &layered{grid: g, width: g.Width}
+	for layer, factor := range weights {
+		if factor != 0 {
+			weighter.terms = append(weighter.terms, term{values: g.layers[layer], factor: factor})
+		}
+	}
+	return weighter
+}
+
+// layered is the Weighter of the Weights, it only keeps the layers with a factor
+type layered struct {
+	grid  *Grid
+	width int
+	terms []term
+
+	// mu guards the table of the jump point search, built for the version of the grid
+	mu      sync.Mutex
+	version int
+	jumps   *jumpTable
+}
+
+type term struct {
+	values []float64
+	factor float64
+}
+
+// GetWeight implements Weighter
+func (l *layered) GetWeight(x, y float64) float64 {
+	index := int(y)*l.width + int(x)
+	weight := 1.0
+	for _, term := range l.terms {
+		weight += term.factor * term.values[index]
+	}
+	return weight
+}
+
+// paint records the type in its layer, painting Empty clears every painted layer of the tile
+func (g *Grid) paint(tile *Tile, value TileType) {
+	g.revision++
+	index := tile.PathIndex()
+	if value == Empty {
+		for _, layer := range layerOf {
+			g.layers[layer][index] = 0
+		}
+		return
+	}
+	layer, ok := layerOf[value]
+	if !ok {
+		return
+	}
+	if layer == ShotRangeLayer {
+		g.layers[layer][index] = math.Min(g.layers[layer][index]+1, maxShotRanges)
+		return
+	}
+	g.layers[layer][index] = 1
+}
@@ -0,0 +1,83 @@
+package navigation_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+var _ = Describe("Layers", func() {
+	It("Should keep every type painted on a tile", func() {
+		grid := navigation.NewGrid(20, 20)
+		grid.PaintShip(10, 10, 4)
+		grid.PaintShip(12, 10, 4)
+		grid.PaintPlanet(10, 10, 1)
+		index := grid.GetTile(10, 10).PathIndex()
+
+		Expect(grid.GetTile(10, 10).Type).To(Equal(navigation.Blocked))
+		Expect(grid.Layer(navigation.PlanetLayer)[index]).To(Equal(1.0))
+		Expect(grid.Layer(navigation.ShipLayer)[index]).To(Equal(1.0))
+		Expect(grid.Layer(navigation.ShotRangeLayer)[index]).To(Equal(2.0))
+	})
+	It("Should clear the painted layers when the tile is painted Empty", func() {
+		grid := navigation.NewGrid(20, 20)
+		grid.PaintShip(10, 10, 4)
+		grid.Paint(10, 10, 6, navigation.Empty)
+
+		tile := grid.GetTile(10, 10)
+		Expect(grid.Weighter.GetWeight(tile.X, tile.Y)).To(Equal(1.0))
+	})
+	It("Should weight the tiles like their type with the default weights", func() {
+		grid := navigation.NewGrid(40, 40)
+		grid.PaintPlanet(20, 20, 5)
+		grid.PaintShip(5, 5, 0)
+		grid.Influence.AddShip(30, 5, 1, 2, 4, true)
+
+		for _, tile := range []*navigation.Tile{grid.GetTile(20, 27), grid.GetTile(6, 5), grid.GetTile(0, 39)} {
+			Expect(grid.Weighter.GetWeight(tile.X, tile.Y)).To(Equal(float64(tile.Type)+1), tile.String())
+		}
+		tile := grid.GetTile(30, 5)
+		Expect(grid.Weighter.GetWeight(tile.X, tile.Y)).To(Equal(1 + navigation.ThreatWeight))
+	})
+	It("Should stop counting shot ranges at ShotRange3", func() {
+		grid := navigation.NewGrid(20, 20)
+		for i := 0; i < 5; i++ {
+			grid.Paint(10, 10, 3, navigation.ShotRange)
+		}
+		tile := grid.GetTile(10, 10)
+
+		Expect(tile.Type).To(Equal(navigation.ShotRange3))
+		Expect(grid.Layer(navigation.ShotRangeLayer)[tile.PathIndex()]).To(Equal(3.0))
+		Expect(grid.Weighter.GetWeight(tile.X, tile.Y)).To(Equal(float64(tile.Type) + 1))
+	})
+	It("Should combine the layers with the weights", func() {
+		grid := navigation.NewGrid(20, 20)
+		grid.PaintPlanet(10, 10, 2)
+		grid.Influence.AddShip(10, 15, 1, 2, 2, true)
+		weighter := grid.Weighting(navigation.Weights{navigation.SafeMarginLayer: 3, navigation.EnemyFireLayer: 10})
+
+		tile := grid.GetTile(10, 15)
+		Expect(weighter.GetWeight(tile.X, tile.Y)).To(Equal(1 + 3 + 10.0))
+	})
+	It("Should find different paths for different weights on the same grid", func() {
+		grid := navigation.NewGrid(40, 21)
+		grid.Influence.AddShip(20, 10, 1, 6, 8, true)
+		from, to := grid.GetTile(0, 10), grid.GetTile(39, 10)
+		fearless := grid.Weighting(navigation.DefaultWeights.With(navigation.EnemyFireLayer, 0))
+
+		path, distance, found, _, _ := grid.Path(context.Background(), from, to, -1)
+		straight, straightDistance, straightFound, _, _ := grid.PathWeighted(context.Background(), fearless, from, to, -1)
+
+		Expect(found).To(BeTrue())
+		Expect(straightFound).To(BeTrue())
+		Expect(straightDistance).To(BeNumerically("~", 39, 1e-9))
+		Expect(distance).To(BeNumerically(">", straightDistance))
+		for _, tile := range straight {
+			Expect(tile.Y).To(Equal(10.0))
+		}
+		Expect(path).To(ContainElement(WithTransform(func(tile *navigation.Tile) bool { return tile.Y != 10 }, BeTrue())))
+	})
+})
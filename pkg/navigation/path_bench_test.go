@@ -0,0 +1,68 @@
+package navigation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metalblueberry/halite-bot/pkg/astar"
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+func benchmarkPath(b *testing.B, width, height int, path func(grid *navigation.Grid, from, to *navigation.Tile)) {
+	grid := obstacleGrid(width, height)
+	from, to := grid.GetTile(0, 0), grid.GetTile(float64(width-1), float64(height-1))
+	// The first search allocates the buffers that the grid keeps for the next ones
+	path(grid, from, to)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		path(grid, from, to)
+	}
+}
+
+func genericPath(grid *navigation.Grid, from, to *navigation.Tile) {
+	astar.PathContext(context.Background(), from, to, -1)
+}
+
+func gridPath(grid *navigation.Grid, from, to *navigation.Tile) {
+	grid.Path(context.Background(), from, to, -1)
+}
+
+func BenchmarkPathGeneric240x160(b *testing.B) { benchmarkPath(b, 240, 160, genericPath) }
+func BenchmarkPathGrid240x160(b *testing.B)    { benchmarkPath(b, 240, 160, gridPath) }
+func BenchmarkPathGeneric384x256(b *testing.B) { benchmarkPath(b, 384, 256, genericPath) }
+func BenchmarkPathGrid384x256(b *testing.B)    { benchmarkPath(b, 384, 256, gridPath) }
+
+// The jump point search keeps its jump table on the weighter of the grid, so only the first search builds it.
+// On 384x256 it takes around 20ms per path while A* takes around 45ms
+func jumpPointPath(grid *navigation.Grid, from, to *navigation.Tile) {
+	grid.Pathfinder = navigation.JumpPoint
+	grid.Path(context.Background(), from, to, -1)
+}
+
+func BenchmarkPathJumpPoint240x160(b *testing.B) { benchmarkPath(b, 240, 160, jumpPointPath) }
+func BenchmarkPathJumpPoint384x256(b *testing.B) { benchmarkPath(b, 384, 256, jumpPointPath) }
+
+func thetaStarPath(grid *navigation.Grid, from, to *navigation.Tile) {
+	grid.Pathfinder = navigation.ThetaStar
+	grid.Path(context.Background(), from, to, -1)
+}
+
+func BenchmarkPathThetaStar240x160(b *testing.B) { benchmarkPath(b, 240, 160, thetaStarPath) }
+func BenchmarkPathThetaStar384x256(b *testing.B) { benchmarkPath(b, 384, 256, thetaStarPath) }
+
+func BenchmarkHierarchy384x256(b *testing.B) {
+	grid := obstacleGrid(384, 256)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		navigation.NewHierarchy(grid, 16)
+	}
+}
+
+func BenchmarkPathHierarchy384x256(b *testing.B) {
+	hierarchy := navigation.NewHierarchy(obstacleGrid(384, 256), 16)
+	benchmarkPath(b, 384, 256, func(grid *navigation.Grid, from, to *navigation.Tile) {
+		hierarchy.Update(grid)
+		hierarchy.Path(context.Background(), from, to, -1)
+	})
+}
@@ -0,0 +1,80 @@
+package navigation_test
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/astar"
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+// obstacleGrid creates a grid with a few planets and ships in the way
+func obstacleGrid(width, height int) *navigation.Grid {
+	grid := navigation.NewGrid(width, height)
+	w, h := float64(width), float64(height)
+	grid.PaintPlanet(math.Round(w/2), math.Round(h/2), h/6)
+	grid.PaintPlanet(math.Round(w/4), math.Round(h/3), h/10)
+	grid.PaintPlanet(math.Round(3*w/4), math.Round(2*h/3), h/10)
+	grid.PaintShip(math.Round(w/3), math.Round(3*h/4), 0)
+	grid.Influence.AddShip(2*w/3, h/4, 1, 6, 13, true)
+	return grid
+}
+
+var _ = Describe("Path", func() {
+	It("Should find the same cost as the generic search", func() {
+		grid := obstacleGrid(120, 80)
+		pairs := [][4]float64{{0, 0, 119, 79}, {0, 40, 119, 40}, {60, 0, 60, 79}, {10, 70, 110, 5}}
+		for _, pair := range pairs {
+			from, to := grid.GetTile(pair[0], pair[1]), grid.GetTile(pair[2], pair[3])
+
+			_, expected, expectedFound, _, _ := astar.PathContext(context.Background(), from, to, -1)
+			path, distance, found, _, _ := grid.Path(context.Background(), from, to, -1)
+
+			Expect(found).To(Equal(expectedFound))
+			Expect(distance).To(BeNumerically("~", expected, 1e-9))
+			Expect(path[0]).To(Equal(from))
+			Expect(path[len(path)-1]).To(Equal(to))
+		}
+	})
+	It("Should not be affected by the previous searches", func() {
+		grid := obstacleGrid(120, 80)
+		from, to := grid.GetTile(0, 0), grid.GetTile(119, 79)
+		_, first, _, _, _ := grid.Path(context.Background(), from, to, -1)
+
+		grid.Path(context.Background(), grid.GetTile(0, 79), grid.GetTile(119, 0), -1)
+		_, second, _, _, _ := grid.Path(context.Background(), from, to, -1)
+
+		Expect(second).To(Equal(first))
+	})
+	It("Should search grids of different sizes", func() {
+		small, big := obstacleGrid(20, 10), obstacleGrid(200, 100)
+		big.Searchers = small.Searchers
+
+		_, _, found, _, _ := small.Path(context.Background(), small.GetTile(0, 0), small.GetTile(19, 0), -1)
+		Expect(found).To(BeTrue())
+		_, _, found, _, _ = big.Path(context.Background(), big.GetTile(0, 0), big.GetTile(199, 0), -1)
+		Expect(found).To(BeTrue())
+	})
+	It("Should keep the search buffers after a garbage collection", func() {
+		grid := obstacleGrid(120, 80)
+		from, to := grid.GetTile(0, 0), grid.GetTile(119, 79)
+		grid.Path(context.Background(), from, to, -1)
+		runtime.GC()
+
+		allocs := testing.AllocsPerRun(5, func() {
+			grid.Path(context.Background(), from, to, -1)
+		})
+		Expect(allocs).To(BeNumerically("<=", 4))
+	})
+	It("Should list the same neighbors when appending to a buffer", func() {
+		grid := obstacleGrid(20, 10)
+		for _, tile := range grid.Tiles {
+			Expect(tile.AppendPathNeighbors([]astar.Pather{})).To(Equal(tile.PathNeighbors()))
+		}
+	})
+})
@@ -0,0 +1,277 @@
+package navigation
+
+import (
+	"math"
+
+	"github.com/metalblueberry/halite-bot/pkg/astar"
+)
+
+// Reservations is the space-time reservation table of cooperative pathfinding (WHCA*), the time is measured in turns.
+// Pilots reserve the tiles they cross during the next turns and the searches planned later avoid the tiles reserved
+// at the turn they would cross them, or wait for them to be released. Turn 1 is the current turn and turns after the
+// Window are never reserved
+type Reservations struct {
+	grid *Grid
+	// Window is the number of turns covered by the table
+	Window int
+	// Speed is the distance flown in a turn
+	Speed  float64
+	owners map[reservation]int
+}
+
+type reservation struct {
+	turn, index int
+}
+
+func NewReservations(grid *Grid, window int, speed float64) *Reservations {
+	return &Reservations{
+		grid:   grid,
+		Window: window,
+		Speed:  speed,
+		owners: make(map[reservation]int),
+	}
+}
+
+// Reserve reserves the tile for the pilot at the turn. The tile is also marked in the ReservationLayer, so the
+// weightings that want to keep away from the lanes of other pilots at any turn can do it
+func (r *Reservations) Reserve(turn int, tile *Tile, id int) {
+	if turn < 1 || turn > r.Window {
+		return
+	}
+	r.owners[reservation{turn: turn, index: tile.PathIndex()}] = id
+	r.grid.layers[ReservationLayer][tile.PathIndex()] = 1
+	r.grid.revision++
+}
+
+// Owner returns the pilot that reserved the tile at the turn
+func (r *Reservations) Owner(turn int, tile *Tile) (id int, ok bool) {
+	id, ok = r.owners[reservation{turn: turn, index: tile.PathIndex()}]
+	return id, ok
+}
+
+// Turn is the turn at which a path of the cost gets to its last tile, see schedule. The turns after the Window are
+// all Window+1
+func (r *Reservations) Turn(cost float64) int {
+	turns := math.Min(cost/r.turnCost(), float64(r.Window))
+	return int(turns) + 1
+}
+
+// turnCost is the cost of flying a turn at full speed over tiles of weight 1
+func (r *Reservations) turnCost() float64 {
+	return r.Speed * r.grid.Scale
+}
+
+// ReserveRoute reserves the tiles crossed by a pilot that follows the route at full speed until the end of the
+// window. The route starts at the position of the pilot, that stays at the last point once it gets there
+func (r *Reservations) ReserveRoute(id int, route ...Positioner) {
+	x, y := route[0].Position()
+	turn, left := 1, r.Speed
+	for _, point := range route[1:] {
+		px, py := point.Position()
+		for turn <= r.Window {
+			distance := math.Hypot(px-x, py-y)
+			if distance <= left {
+				r.reserveLine(turn, x, y, px, py, id)
+				x, y, left = px, py, left-distance
+				break
+			}
+			nx, ny := x+(px-x)*left/distance, y+(py-y)*left/distance
+			r.reserveLine(turn, x, y, nx, ny, id)
+			x, y, turn, left = nx, ny, turn+1, r.Speed
+		}
+	}
+	for ; turn <= r.Window; turn++ {
+		r.reserveLine(turn, x, y, x, y, id)
+	}
+}
+
+func (r *Reservations) reserveLine(turn int, x1, y1, x2, y2 float64, id int) {
+	from, to := r.grid.GetTile(x1, y1), r.grid.GetTile(x2, y2)
+	if from == nil || to == nil {
+		return
+	}
+	for _, tile := range r.grid.TilesBetween(from, to) {
+		r.Reserve(turn, tile, id)
+	}
+}
+
+// Reserved reports if any tile of the path is reserved at the turn that a pilot following it gets there. The turns
+// come from the cost of the path with the weights of the grid, like the searches do, and a repeated tile waits a
+// turn on it. Steps to a neighbor only check the neighbor, longer segments check every tile they cross.
+// A nil table reserves nothing
+func (r *Reservations) Reserved(path []*Tile) bool {
+	schedule := r.schedule(nil)
+	if schedule == nil {
+		return false
+	}
+	cost := 0.0
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+		if distance := from.gridDistance(to); distance > math.Sqrt2 {
+			if schedule.crosses(r.grid.Weighter, from, to, cost) {
+				return true
+			}
+			cost += distance * to.weight()
+			continue
+		}
+		if from == to {
+			cost += r.turnCost()
+		} else {
+			cost += from.gridDistance(to) * to.weight()
+		}
+		if schedule.reserved(to, cost) {
+			return true
+		}
+	}
+	return false
+}
+
+// schedule measures the time of the searches on a grid with reservations. The time of a path is its cost, a turn
+// lasts the cost of flying at full speed over tiles of weight 1, so the expensive tiles are also slow to cross.
+// The nodes of the searches are moments, the tiles at the turn the path gets there, and the pilots can wait a turn
+// on a tile while it is not reserved. A nil schedule, for grids without reservations, searches the tiles
+type schedule struct {
+	reservations *Reservations
+	// goal is a single moment after the window, the search ends whatever the turn it gets there
+	goal *Tile
+}
+
+// schedule returns the schedule of the searches to the goal, nil for a nil table
+func (r *Reservations) schedule(goal *Tile) *schedule {
+	if r == nil {
+		return nil
+	}
+	return &schedule{reservations: r, goal: goal}
+}
+
+// moment is a tile at a turn. The turns after the window are all the same, Window+1, since nothing is reserved then
+type moment struct {
+	tile *Tile
+	turn int
+}
+
+// at returns the moment at which a path of the cost gets to the tile
+func (s *schedule) at(tile *Tile, cost float64) moment {
+	if tile == s.goal {
+		return moment{tile: tile, turn: s.reservations.Window + 1}
+	}
+	return moment{tile: tile, turn: s.reservations.Turn(cost)}
+}
+
+// node is the node of the search for the tile reached with a path of the cost, the tile itself without schedule
+func (s *schedule) node(tile *Tile, cost float64) astar.Pather {
+	if s == nil {
+		return tile
+	}
+	return s.at(tile, cost)
+}
+
+// reserved reports if the tile is reserved at the turn a path of the cost gets there
+func (s *schedule) reserved(tile *Tile, cost float64) bool {
+	if s == nil {
+		return false
+	}
+	_, reserved := s.reservations.Owner(s.reservations.Turn(cost), tile)
+	return reserved
+}
+
+// crosses reports if the line between the tiles crosses a tile reserved at the turn it gets there. The path to from
+// has the cost and the line costs the weight of to at every tile, like the line of sight checks
+func (s *schedule) crosses(weighter Weighter, from, to *Tile, cost float64) bool {
+	if s == nil {
+		return false
+	}
+	weight := to.weightWith(weighter)
+	return !s.reservations.grid.walkLine(from, to, func(tile *Tile) bool {
+		return tile == from || !s.reserved(tile, cost+from.gridDistance(tile)*weight)
+	})
+}
+
+// timed tells if a path of the cost is still inside the window, where the reservations change with the turns
+func (s *schedule) timed(cost float64) bool {
+	return s != nil && s.reservations.Turn(cost) <= s.reservations.Window
+}
+
+// appendWait adds staying a turn on the tile of the node, unless it is reserved by then
+func (s *schedule) appendWait(successors []astar.Successor, node astar.Pather, cost float64) []astar.Successor {
+	if !s.timed(cost) {
+		return successors
+	}
+	tile, wait := tileOf(node), s.reservations.turnCost()
+	if s.reserved(tile, cost+wait) {
+		return successors
+	}
+	return append(successors, astar.Successor{Node: s.at(tile, cost+wait), Cost: wait})
+}
+
+// tileOf returns the tile of a node of the searches, a tile or a moment
+func tileOf(node astar.Pather) *Tile {
+	if m, ok := node.(moment); ok {
+		return m.tile
+	}
+	return node.(*Tile)
+}
+
+// PathIndex implements astar.Indexed, every turn of the window and the one after it have a copy of the tiles
+func (m moment) PathIndex() int {
+	return (m.turn-1)*len(m.tile.Grid.Tiles) + m.tile.PathIndex()
+}
+
+// PathNeighbors returns the tiles around at the same turn. The searches only link moments like that after the
+// window, where the time does not change the reservations
+func (m moment) PathNeighbors() []astar.Pather {
+	return m.AppendPathNeighbors(make([]astar.Pather, 0, 8))
+}
+
+// AppendPathNeighbors works like PathNeighbors but appends the neighbors to the given slice
+func (m moment) AppendPathNeighbors(neighbors []astar.Pather) []astar.Pather {
+	first := len(neighbors)
+	neighbors = m.tile.AppendPathNeighbors(neighbors)
+	for i := first; i < len(neighbors); i++ {
+		neighbors[i] = moment{tile: neighbors[i].(*Tile), turn: m.turn}
+	}
+	return neighbors
+}
+
+// PathNeighborCost is the cost of moving between the tiles
+func (m moment) PathNeighborCost(to astar.Pather) float64 {
+	return m.tile.PathNeighborCost(tileOf(to))
+}
+
+// PathEstimatedCost is the heuristic of the tiles, waiting only adds cost
+func (m moment) PathEstimatedCost(to astar.Pather) float64 {
+	return m.tile.PathEstimatedCost(tileOf(to))
+}
+
+// cooperative is the A* expander of the grids with reservations or other weighter than the one of the grid. It skips
+// the neighbors reserved at the turn it gets there and it can wait for them
+type cooperative struct {
+	grid     *Grid
+	weighter Weighter
+	schedule *schedule
+}
+
+// Expand implements astar.Expander
+func (c cooperative) Expand(current, parent, goal astar.Pather, cost, parentCost float64, successors []astar.Successor) []astar.Successor {
+	tile := tileOf(current)
+	if tile.Type == Blocked {
+		return successors
+	}
+	x, y := int(tile.X), int(tile.Y)
+	for _, step := range neighborSteps {
+		nx, ny := x+step.dx, y+step.dy
+		if nx < 0 || nx >= c.grid.Width || ny < 0 || ny >= c.grid.Height {
+			continue
+		}
+		neighbor := c.grid.Tiles[ny*c.grid.Width+nx]
+		if neighbor.Type == Blocked {
+			continue
+		}
+		stepCost := tile.gridDistance(neighbor) * neighbor.weightWith(c.weighter)
+		if c.schedule.reserved(neighbor, cost+stepCost) {
+			continue
+		}
+		successors = append(successors, astar.Successor{Node: c.schedule.node(neighbor, cost+stepCost), Cost: stepCost})
+	}
+	return c.schedule.appendWait(successors, current, cost)
+}
@@ -0,0 +1,137 @@
+package navigation_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+// reserveWall reserves the column x of the grid for the pilot 0 at the turn
+func reserveWall(grid *navigation.Grid, x float64, turn int) {
+	for y := 0; y < grid.Height; y++ {
+		grid.Reservations.Reserve(turn, grid.GetTile(x, float64(y)), 0)
+	}
+}
+
+var _ = Describe("Reservations", func() {
+	var grid *navigation.Grid
+	BeforeEach(func() {
+		grid = navigation.NewGrid(30, 9)
+		grid.Reservations = navigation.NewReservations(grid, 3, 7)
+	})
+	It("Should reserve the tiles crossed at every turn of the route", func() {
+		grid.Reservations.ReserveRoute(4, grid.GetTile(0, 4), grid.GetTile(17, 4))
+
+		for _, reserved := range []struct {
+			x    float64
+			turn int
+		}{{0, 1}, {7, 1}, {7, 2}, {10, 2}, {14, 3}, {17, 3}} {
+			id, ok := grid.Reservations.Owner(reserved.turn, grid.GetTile(reserved.x, 4))
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(4))
+		}
+		_, ok := grid.Reservations.Owner(1, grid.GetTile(10, 4))
+		Expect(ok).To(BeFalse())
+		_, ok = grid.Reservations.Owner(3, grid.GetTile(3, 4))
+		Expect(ok).To(BeFalse())
+		Expect(grid.GetTile(10, 4).Type).To(Equal(navigation.Empty))
+		Expect(grid.Layer(navigation.ReservationLayer)[grid.GetTile(10, 4).PathIndex()]).To(Equal(1.0))
+	})
+	It("Should keep the last tile reserved until the end of the window", func() {
+		grid.Reservations.ReserveRoute(1, grid.GetTile(5, 5))
+
+		for turn := 1; turn <= 3; turn++ {
+			_, ok := grid.Reservations.Owner(turn, grid.GetTile(5, 5))
+			Expect(ok).To(BeTrue())
+		}
+		_, ok := grid.Reservations.Owner(4, grid.GetTile(5, 5))
+		Expect(ok).To(BeFalse())
+	})
+	It("Should measure the turns with the cost of the paths", func() {
+		Expect(grid.Reservations.Turn(6)).To(Equal(1))
+		Expect(grid.Reservations.Turn(7)).To(Equal(2))
+		Expect(grid.Reservations.Turn(15)).To(Equal(3))
+		Expect(grid.Reservations.Turn(1000)).To(Equal(4))
+	})
+	It("Should tell if a path crosses a tile reserved at the turn it gets there", func() {
+		reserveWall(grid, 4, 1)
+		reserveWall(grid, 12, 1)
+		row := func(xs ...float64) []*navigation.Tile {
+			path := make([]*navigation.Tile, len(xs))
+			for i, x := range xs {
+				path[i] = grid.GetTile(x, 4)
+			}
+			return path
+		}
+
+		Expect(grid.Reservations.Reserved(row(1, 2, 3))).To(BeFalse())
+		Expect(grid.Reservations.Reserved(row(1, 2, 3, 4))).To(BeTrue())
+		Expect(grid.Reservations.Reserved(row(1, 2, 3, 3, 4))).To(BeFalse())
+		Expect(grid.Reservations.Reserved(row(4, 5, 12))).To(BeFalse())
+		Expect(grid.Reservations.Reserved(row(5, 12))).To(BeFalse())
+		Expect(grid.Reservations.Reserved(row(1, 5))).To(BeTrue())
+	})
+	for _, pathfinder := range []navigation.Pathfinder{navigation.AStar, navigation.JumpPoint, navigation.ThetaStar} {
+		pathfinder := pathfinder
+		It("Should wait until the tiles reserved at the same turn are released with "+pathfinder.String(), func() {
+			grid.Pathfinder = pathfinder
+			reserveWall(grid, 4, 1)
+
+			path, _, found, _, _ := grid.Path(context.Background(), grid.GetTile(1, 4), grid.GetTile(28, 4), -1)
+
+			Expect(found).To(BeTrue())
+			Expect(grid.Reservations.Reserved(path)).To(BeFalse())
+			Expect(waits(path)).To(Equal(1))
+		})
+		It("Should cross the tiles reserved at other turns with "+pathfinder.String(), func() {
+			grid.Pathfinder = pathfinder
+			reserveWall(grid, 4, 3)
+
+			path, distance, found, _, _ := grid.Path(context.Background(), grid.GetTile(1, 4), grid.GetTile(28, 4), -1)
+
+			Expect(found).To(BeTrue())
+			Expect(waits(path)).To(Equal(0))
+			Expect(distance).To(BeNumerically("~", 27, 1e-9))
+		})
+		It("Should take the turn from the cost of the path with "+pathfinder.String(), func() {
+			grid.Pathfinder = pathfinder
+			// The slow tiles make the pilot get to the wall in the second turn, although it is 3 tiles away
+			for y := 0; y < grid.Height; y++ {
+				for _, x := range []float64{2, 3} {
+					grid.Layer(navigation.SafeMarginLayer)[grid.GetTile(x, float64(y)).PathIndex()] = 1
+				}
+			}
+			grid.Invalidate()
+			reserveWall(grid, 4, 2)
+
+			path, _, found, _, _ := grid.Path(context.Background(), grid.GetTile(1, 4), grid.GetTile(28, 4), -1)
+
+			Expect(found).To(BeTrue())
+			Expect(grid.Reservations.Reserved(path)).To(BeFalse())
+			Expect(waits(path)).To(Equal(1))
+		})
+		It("Should go around the route of another pilot with "+pathfinder.String(), func() {
+			grid.Pathfinder = pathfinder
+			grid.Reservations.ReserveRoute(0, grid.GetTile(10, 0), grid.GetTile(10, 8))
+
+			path, _, found, _, _ := grid.Path(context.Background(), grid.GetTile(7, 4), grid.GetTile(14, 4), -1)
+
+			Expect(found).To(BeTrue())
+			Expect(grid.Reservations.Reserved(path)).To(BeFalse())
+		})
+	}
+})
+
+// waits counts the turns waited along the path, the repeated tiles
+func waits(path []*navigation.Tile) int {
+	count := 0
+	for i := 1; i < len(path); i++ {
+		if path[i] == path[i-1] {
+			count++
+		}
+	}
+	return count
+}
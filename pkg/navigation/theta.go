@@ -0,0 +1,154 @@
+package navigation
+
+import (
+	"math"
+
+	"github.com/metalblueberry/halite-bot/pkg/astar"
+)
+
+// obstacle is a circle painted as Blocked, line of sight checks use its exact shape because the tiles only
+// approximate it
+type obstacle struct {
+	X, Y, R float64
+}
+
+// anyAngle is Lazy Theta*. The neighbors of the expanded tile are linked directly to the parent of the tile assuming
+// there is line of sight between them, the line is checked when the neighbor is expanded. The paths are made of
+// straight segments at any angle instead of 45 degree steps. Inside the window of the reservations the lines are
+// checked right away, because the reservations they cross depend on the cost of the path to the parent
+type anyAngle struct {
+	grid     *Grid
+	weighter Weighter
+	schedule *schedule
+}
+
+// Expand implements astar.Expander
+func (a anyAngle) Expand(current, parent, goal astar.Pather, cost, parentCost float64, successors []astar.Successor) []astar.Successor {
+	tile := tileOf(current)
+	if tile.Type == Blocked {
+		return successors
+	}
+	// A tile reached waiting is linked to itself, the neighbors can not skip the wait
+	var from *Tile
+	if parent != nil && tileOf(parent) != tile {
+		from = tileOf(parent)
+	}
+	x, y := int(tile.X), int(tile.Y)
+	for _, step := range neighborSteps {
+		nx, ny := x+step.dx, y+step.dy
+		if nx < 0 || nx >= a.grid.Width || ny < 0 || ny >= a.grid.Height {
+			continue
+		}
+		neighbor := a.grid.Tiles[ny*a.grid.Width+nx]
+		if neighbor.Type == Blocked {
+			continue
+		}
+		weight := neighbor.weightWith(a.weighter)
+		if from != nil {
+			linked := parentCost + from.gridDistance(neighbor)*weight
+			if !a.schedule.timed(parentCost) {
+				successors = append(successors, astar.Successor{Node: a.schedule.node(neighbor, linked), Cost: linked - parentCost, From: parent, Unchecked: true})
+				continue
+			}
+			if !a.schedule.reserved(neighbor, linked) && a.grid.lineOfSight(a.weighter, from, neighbor) && !a.schedule.crosses(a.weighter, from, neighbor, parentCost) {
+				successors = append(successors, astar.Successor{Node: a.schedule.node(neighbor, linked), Cost: linked - parentCost, From: parent})
+				continue
+			}
+		}
+		stepCost := tile.gridDistance(neighbor) * weight
+		if a.schedule.reserved(neighbor, cost+stepCost) {
+			continue
+		}
+		successors = append(successors, astar.Successor{Node: a.schedule.node(neighbor, cost+stepCost), Cost: stepCost})
+	}
+	return a.schedule.appendWait(successors, current, cost)
+}
+
+// Verify implements astar.Verifier, the links checked late are after the window of the reservations
+func (a anyAngle) Verify(parent, node astar.Pather) bool {
+	return a.grid.lineOfSight(a.weighter, tileOf(parent), tileOf(node))
+}
+
+// LineOfSight reports if a ship can fly in straight line between the tiles paying at most the cost of the destination
+// at every tile it crosses. The line can not cross Blocked tiles nor the planets painted in the grid
+func (g *Grid) LineOfSight(from, to *Tile) bool {
+	return g.lineOfSight(g.Weighter, from, to)
+}
+
+func (g *Grid) lineOfSight(weighter Weighter, from, to *Tile) bool {
+	weight := to.weightWith(weighter)
+	visible := g.walkLine(from, to, func(tile *Tile) bool {
+		return tile == from || tile.Type != Blocked && tile.weightWith(weighter) <= weight
+	})
+	if !visible {
+		return false
+	}
+	x1, y1 := from.Position()
+	x2, y2 := to.Position()
+	for _, obstacle := range g.obstacles {
+		if segmentDistance(x1, y1, x2, y2, obstacle.X, obstacle.Y) <= obstacle.R {
+			return false
+		}
+	}
+	return true
+}
+
+// TilesBetween returns the tiles crossed by the straight line between the tiles, both included.
+// When the line goes through the corner of a tile, the two tiles that share the corner are included too
+func (g *Grid) TilesBetween(from, to *Tile) []*Tile {
+	tiles := []*Tile{}
+	g.walkLine(from, to, func(tile *Tile) bool {
+		tiles = append(tiles, tile)
+		return true
+	})
+	return tiles
+}
+
+// walkLine calls visit with the tiles crossed by the line from the centre of a tile to the centre of the other in order.
+// It stops when visit returns false and reports if all the tiles were visited
+func (g *Grid) walkLine(from, to *Tile, visit func(*Tile) bool) bool {
+	x, y := int(from.X), int(from.Y)
+	dx, dy := int(to.X)-x, int(to.Y)-y
+	sx, sy := 1, 1
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	if dy < 0 {
+		dy, sy = -dy, -1
+	}
+
+	if !visit(from) {
+		return false
+	}
+	for ix, iy := 0, 0; ix < dx || iy < dy; {
+		// Compares where the line leaves the tile, across a vertical side, a horizontal side or the corner
+		switch decision := (1+2*ix)*dy - (1+2*iy)*dx; {
+		case decision == 0:
+			if !visit(g.Tiles[y*g.Width+x+sx]) || !visit(g.Tiles[(y+sy)*g.Width+x]) {
+				return false
+			}
+			x, y = x+sx, y+sy
+			ix, iy = ix+1, iy+1
+		case decision < 0:
+			x, ix = x+sx, ix+1
+		default:
+			y, iy = y+sy, iy+1
+		}
+		if !visit(g.Tiles[y*g.Width+x]) {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentDistance is the distance from the point p to the segment from a to b
+func segmentDistance(ax, ay, bx, by, px, py float64) float64 {
+	vx, vy := bx-ax, by-ay
+	length := vx*vx + vy*vy
+	t := 0.0
+	if length > 0 {
+		t = math.Max(0, math.Min(1, ((px-ax)*vx+(py-ay)*vy)/length))
+	}
+	x, y := ax+t*vx-px, ay+t*vy-py
+	return math.Sqrt(x*x + y*y)
+}
@@ -0,0 +1,92 @@
+package navigation_test
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/navigation"
+)
+
+var _ = Describe("Theta*", func() {
+	It("Should go in straight line in open space", func() {
+		grid := navigation.NewGrid(20, 10)
+		grid.Pathfinder = navigation.ThetaStar
+		from, to := grid.GetTile(0, 0), grid.GetTile(19, 7)
+
+		path, distance, found, _, _ := grid.Path(context.Background(), from, to, -1)
+
+		Expect(found).To(BeTrue())
+		Expect(path).To(Equal([]*navigation.Tile{from, to}))
+		Expect(distance).To(BeNumerically("~", math.Hypot(19, 7), 1e-9))
+	})
+	It("Should go around planets with waypoints in line of sight", func() {
+		grid := navigation.NewGrid(40, 30)
+		grid.PaintPlanet(20, 15, 6)
+		grid.Pathfinder = navigation.ThetaStar
+		from, to := grid.GetTile(2, 14), grid.GetTile(37, 16)
+
+		path, distance, found, _, _ := grid.Path(context.Background(), from, to, -1)
+
+		Expect(found).To(BeTrue())
+		Expect(len(path)).To(BeNumerically("<=", 5))
+		for i := 1; i < len(path); i++ {
+			Expect(grid.LineOfSight(path[i-1], path[i])).To(BeTrue())
+			for _, tile := range grid.TilesBetween(path[i-1], path[i]) {
+				Expect(tile.Type).NotTo(Equal(navigation.Blocked))
+			}
+		}
+
+		grid.Pathfinder = navigation.AStar
+		_, tileDistance, _, _, _ := grid.Path(context.Background(), from, to, -1)
+		Expect(distance).To(BeNumerically("<", tileDistance))
+	})
+	It("Should not be cheaper than A* when it crosses expensive tiles", func() {
+		grid := obstacleGrid(120, 80)
+		pairs := [][4]float64{{0, 0, 119, 79}, {0, 40, 119, 40}, {60, 0, 60, 79}, {10, 70, 110, 5}}
+		for _, pair := range pairs {
+			from, to := grid.GetTile(pair[0], pair[1]), grid.GetTile(pair[2], pair[3])
+
+			grid.Pathfinder = navigation.AStar
+			_, expected, _, _, _ := grid.Path(context.Background(), from, to, -1)
+			grid.Pathfinder = navigation.ThetaStar
+			path, distance, found, _, _ := grid.Path(context.Background(), from, to, -1)
+
+			Expect(found).To(BeTrue())
+			Expect(distance).To(BeNumerically("<=", expected+1e-9))
+			Expect(path[0]).To(Equal(from))
+			Expect(path[len(path)-1]).To(Equal(to))
+		}
+	})
+	It("Should not see through the corners of planets", func() {
+		grid := navigation.NewGrid(30, 30)
+		grid.PaintPlanet(15, 15, 5)
+
+		Expect(grid.LineOfSight(grid.GetTile(8, 22), grid.GetTile(22, 8))).To(BeFalse())
+		Expect(grid.LineOfSight(grid.GetTile(0, 0), grid.GetTile(29, 0))).To(BeTrue())
+	})
+	It("Should walk the tiles of a line without gaps", func() {
+		grid := navigation.NewGrid(20, 20)
+
+		tiles := grid.TilesBetween(grid.GetTile(2, 3), grid.GetTile(15, 9))
+
+		Expect(tiles[0]).To(Equal(grid.GetTile(2, 3)))
+		Expect(tiles[len(tiles)-1]).To(Equal(grid.GetTile(15, 9)))
+		for i := 1; i < len(tiles); i++ {
+			Expect(math.Abs(tiles[i].X-tiles[i-1].X) + math.Abs(tiles[i].Y-tiles[i-1].Y)).To(Equal(1.0))
+		}
+	})
+	It("Should include both tiles when the line crosses a corner", func() {
+		grid := navigation.NewGrid(5, 5)
+
+		tiles := grid.TilesBetween(grid.GetTile(0, 0), grid.GetTile(2, 2))
+
+		Expect(tiles).To(ConsistOf(
+			grid.GetTile(0, 0), grid.GetTile(1, 0), grid.GetTile(0, 1),
+			grid.GetTile(1, 1), grid.GetTile(2, 1), grid.GetTile(1, 2),
+			grid.GetTile(2, 2),
+		))
+	})
+})
@@ -7,6 +7,8 @@ import (
 	"github.com/metalblueberry/halite-bot/pkg/astar"
 )
 
+// TileType is the last thing painted on a tile, it tells if the tile can be crossed and how it is printed.
+// The cost of the tiles comes from the layers, see Layer
 type TileType int
 
 const (
@@ -34,12 +36,14 @@ func (t TileType) String() string {
 	return repr[t]
 }
 
+// Weighter gives the cost of moving one unit inside the tile at the grid coordinates, it must be at least 1
 type Weighter interface {
 	GetWeight(float64, float64) float64
 }
 
 type Tile struct {
 	Type TileType
+	// X and Y are the grid coordinates of the tile, Position returns the map coordinates
 	X    float64
 	Y    float64
 	Grid *Grid
@@ -54,8 +58,12 @@ func NewTile(x, y int, Type TileType, Grid *Grid) *Tile {
 	}
 }
 
+// Position returns the centre of the tile in map coordinates, the grid coordinates for tiles without grid
 func (t *Tile) Position() (x, y float64) {
-	return t.X, t.Y
+	if t.Grid == nil {
+		return t.X, t.Y
+	}
+	return t.Grid.ToWorld(t.X, t.Y)
 }
 
 func (t *Tile) String() string {
@@ -66,59 +74,67 @@ type Positioner interface {
 	Position() (x, y float64)
 }
 
+// DistanceTo returns the distance in map units between the centre of the tile and the position
 func (t *Tile) DistanceTo(other Positioner) float64 {
+	x1, y1 := t.Position()
 	x2, y2 := other.Position()
-	return math.Sqrt(math.Pow(float64(t.X-x2), 2) + math.Pow(float64(t.Y-y2), 2))
+	dx, dy := x1-x2, y1-y2
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
+// gridDistance returns the distance in tiles between the tiles, the path costs are measured in tiles
+func (t *Tile) gridDistance(other *Tile) float64 {
+	dx, dy := t.X-other.X, t.Y-other.Y
+	return math.Sqrt(dx*dx + dy*dy)
 }
 
 // PathNeighbors returns the direct neighboring nodes of this node which
 // can be pathed to.
 func (t *Tile) PathNeighbors() []astar.Pather {
+	return t.AppendPathNeighbors(make([]astar.Pather, 0, 8))
+}
+
+// AppendPathNeighbors works like PathNeighbors but appends the neighbors to the given slice
+func (t *Tile) AppendPathNeighbors(neighbors []astar.Pather) []astar.Pather {
 	if t.Type == Blocked {
-		return nil
+		return neighbors
 	}
-	neighbors := make([]astar.Pather, 0, 4)
-
-	appendIfValid := func(item astar.Pather) {
-		switch v := item.(type) {
-		case *Tile:
-			if v != nil && v.Type != Blocked {
-				neighbors = append(neighbors, v)
-			}
-		default:
-			return
+	x, y := int(t.X), int(t.Y)
+	for _, step := range neighborSteps {
+		nx, ny := x+step.dx, y+step.dy
+		if nx < 0 || nx >= t.Grid.Width || ny < 0 || ny >= t.Grid.Height {
+			continue
+		}
+		if neighbor := t.Grid.Tiles[ny*t.Grid.Width+nx]; neighbor.Type != Blocked {
+			neighbors = append(neighbors, neighbor)
 		}
 	}
-
-	up := t.Grid.GetTile(t.X, t.Y-1)
-	appendIfValid(up)
-	down := t.Grid.GetTile(t.X, t.Y+1)
-	appendIfValid(down)
-	left := t.Grid.GetTile(t.X-1, t.Y)
-	appendIfValid(left)
-	right := t.Grid.GetTile(t.X+1, t.Y)
-	appendIfValid(right)
-
-	upleft := t.Grid.GetTile(t.X-1, t.Y-1)
-	appendIfValid(upleft)
-	upright := t.Grid.GetTile(t.X+1, t.Y-1)
-	appendIfValid(upright)
-	downleft := t.Grid.GetTile(t.X-1, t.Y+1)
-	appendIfValid(downleft)
-	downright := t.Grid.GetTile(t.X+1, t.Y+1)
-	appendIfValid(downright)
 	return neighbors
 }
 
+// PathIndex returns the position of the tile in Grid.Tiles
+func (t *Tile) PathIndex() int {
+	return int(t.Y)*t.Grid.Width + int(t.X)
+}
+
 // PathNeighborCost calculates the exact movement cost to neighbor nodes.
 func (t *Tile) PathNeighborCost(to astar.Pather) float64 {
 	toT := to.(*Tile)
-	return t.DistanceTo(toT) * (float64(toT.Type) + 1)
+	return t.gridDistance(toT) * toT.weight()
+}
+
+// weight is the cost of moving one unit inside the tile with the Weighter of the grid
+func (t *Tile) weight() float64 {
+	return t.weightWith(t.Grid.Weighter)
+}
+
+func (t *Tile) weightWith(weighter Weighter) float64 {
+	return weighter.GetWeight(t.X, t.Y)
 }
 
 // PathEstimatedCost is a heuristic method for estimating movement costs
 // between non-adjacent nodes.
 func (t *Tile) PathEstimatedCost(to astar.Pather) float64 {
 	toT := to.(*Tile)
-	return t.DistanceTo(toT)
+	return t.gridDistance(toT)
 }
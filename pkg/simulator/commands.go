@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type commandType byte
+
+const (
+	thrustCommand commandType = 't'
+	dockCommand   commandType = 'd'
+	undockCommand commandType = 'u'
+)
+
+// command is a decoded engine instruction for a single ship
+type command struct {
+	Type      commandType
+	ShipID    int
+	Magnitude int
+	Angle     int
+	PlanetID  int
+}
+
+// parseCommands decodes a command list the same way the engine does, joining
+// everything in a single line and reading it token by token
+func parseCommands(raw []string) ([]command, error) {
+	tokens := strings.Fields(strings.Join(raw, " "))
+	commands := make([]command, 0, len(tokens)/3)
+
+	for len(tokens) > 0 {
+		var c command
+		args := 0
+		switch tokens[0] {
+		case "t":
+			c.Type, args = thrustCommand, 3
+		case "d":
+			c.Type, args = dockCommand, 2
+		case "u":
+			c.Type, args = undockCommand, 1
+		default:
+			return nil, fmt.Errorf("unknown command %q", tokens[0])
+		}
+		if len(tokens) < args+1 {
+			return nil, fmt.Errorf("command %q expects %d arguments, got %d", tokens[0], args, len(tokens)-1)
+		}
+
+		values := make([]int, args)
+		for i := range values {
+			value, err := strconv.Atoi(tokens[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("command %q argument %d: %v", tokens[0], i, err)
+			}
+			values[i] = value
+		}
+
+		c.ShipID = values[0]
+		switch c.Type {
+		case thrustCommand:
+			c.Magnitude, c.Angle = values[1], values[2]
+		case dockCommand:
+			c.PlanetID = values[1]
+		}
+
+		commands = append(commands, c)
+		tokens = tokens[args+1:]
+	}
+	return commands, nil
+}
@@ -0,0 +1,110 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// Generate creates a symmetric starting map for 2 or 4 players.
+// The same seed always produces the same map.
+func Generate(width, height, players int, seed int64) hlt.Map {
+	random := rand.New(rand.NewSource(seed))
+	s := &Simulator{
+		Width:   width,
+		Height:  height,
+		players: players,
+	}
+
+	w, h := float64(width), float64(height)
+	starts := [][2]float64{{w / 4, h / 2}, {3 * w / 4, h / 2}}
+	if players == 4 {
+		starts = [][2]float64{{w / 4, h / 4}, {3 * w / 4, h / 4}, {w / 4, 3 * h / 4}, {3 * w / 4, 3 * h / 4}}
+	}
+	for player := 0; player < players; player++ {
+		x, y := starts[player][0], starts[player][1]
+		for i := 0; i < hlt.Constants.ShipsPerPlayer; i++ {
+			s.ships = append(s.ships, &ship{
+				id:     s.nextShipID,
+				owner:  player,
+				x:      x,
+				y:      y + 2*float64(i-1),
+				radius: hlt.Constants.ShipRadius,
+				health: hlt.Constants.BaseShipHealth,
+			})
+			s.nextShipID++
+		}
+	}
+
+	s.addPlanet(w/2, h/2, math.Min(w, h)/20)
+
+	// Every planet is mirrored so all players get the same conditions
+	mirror := func(x, y float64) [][2]float64 {
+		if players == 4 {
+			return [][2]float64{{x, y}, {w - x, y}, {x, h - y}, {w - x, h - y}}
+		}
+		return [][2]float64{{x, y}, {w - x, h - y}}
+	}
+
+	groups := hlt.Constants.PlanetsPerPlayer
+	for attempt := 0; attempt < 1000 && groups > 0; attempt++ {
+		radius := 3 + random.Float64()*5
+		x := radius + 1 + random.Float64()*(w/2-radius-1)
+		y := radius + 1 + random.Float64()*(h-2*radius-2)
+		if players == 4 {
+			y = radius + 1 + random.Float64()*(h/2-radius-1)
+		}
+
+		positions := mirror(x, y)
+		valid := true
+		for _, position := range positions {
+			if !s.planetFits(position[0], position[1], radius) {
+				valid = false
+			}
+		}
+		if !valid {
+			continue
+		}
+		for _, position := range positions {
+			s.addPlanet(position[0], position[1], radius)
+		}
+		groups--
+	}
+
+	return s.Map(0)
+}
+
+func (s *Simulator) addPlanet(x, y, radius float64) {
+	s.planets = append(s.planets, &planet{
+		id:                 len(s.planets),
+		x:                  x,
+		y:                  y,
+		radius:             radius,
+		health:             math.Round(radius * hlt.Constants.BaseShipHealth),
+		dockingSpots:       math.Max(2, math.Floor(radius/2)),
+		remainingResources: math.Round(radius * hlt.Constants.ResourcesPerRadius),
+	})
+}
+
+// planetFits checks that a new planet leaves enough room to navigate around it and to spawn ships
+func (s *Simulator) planetFits(x, y, radius float64) bool {
+	margin := hlt.Constants.SpawnRadius + 2*hlt.Constants.DockRadius
+	if x-radius-margin < 0 || y-radius-margin < 0 ||
+		x+radius+margin >= float64(s.Width) || y+radius+margin >= float64(s.Height) {
+		return false
+	}
+	position := twoD.NewPosition(x, y)
+	for _, p := range s.planets {
+		if twoD.Distance(p, position) < p.radius+radius+margin {
+			return false
+		}
+	}
+	for _, sh := range s.ships {
+		if twoD.Distance(sh, position) < radius+margin {
+			return false
+		}
+	}
+	return true
+}
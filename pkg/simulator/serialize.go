@@ -0,0 +1,63 @@
+package simulator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GameString encodes the current state in the format the engine sends to the bots every turn
+func (s *Simulator) GameString() string {
+	var b strings.Builder
+	writeInt := func(value int) {
+		b.WriteString(strconv.Itoa(value))
+		b.WriteByte(' ')
+	}
+	writeFloat := func(value float64) {
+		b.WriteString(strconv.FormatFloat(value, 'f', 4, 64))
+		b.WriteByte(' ')
+	}
+
+	writeInt(s.players)
+	for player := 0; player < s.players; player++ {
+		writeInt(player)
+		writeInt(s.ShipCount(player))
+		for _, sh := range s.ships {
+			if sh.owner != player {
+				continue
+			}
+			writeInt(sh.id)
+			writeFloat(sh.x)
+			writeFloat(sh.y)
+			writeInt(int(sh.health))
+			writeFloat(sh.velX)
+			writeFloat(sh.velY)
+			writeInt(int(sh.dockingStatus))
+			writeInt(sh.planetID)
+			writeInt(int(sh.dockingProgress))
+			writeInt(int(sh.weaponCooldown))
+		}
+	}
+
+	writeInt(len(s.planets))
+	for _, p := range s.planets {
+		writeInt(p.id)
+		writeFloat(p.x)
+		writeFloat(p.y)
+		writeInt(int(p.health))
+		writeFloat(p.radius)
+		writeInt(int(p.dockingSpots))
+		writeInt(int(p.production))
+		writeInt(int(p.remainingResources))
+		owned := 0
+		if p.owned {
+			owned = 1
+		}
+		writeInt(owned)
+		writeInt(p.owner)
+		writeInt(len(p.docked))
+		for _, id := range p.docked {
+			writeInt(id)
+		}
+	}
+	return strings.TrimSuffix(b.String(), " ")
+}
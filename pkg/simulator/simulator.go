@@ -0,0 +1,253 @@
+package simulator
+
+import (
+	"sort"
+
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+)
+
+// Simulator reproduces the Halite II engine rules so matches can be played without the halite binary
+type Simulator struct {
+	Width, Height int
+	Turn          int
+
+	players    int
+	ships      []*ship
+	planets    []*planet
+	nextShipID int
+}
+
+type ship struct {
+	id, owner       int
+	x, y            float64
+	radius          float64
+	health          float64
+	velX, velY      float64
+	dockingStatus   hlt.DockingStatus
+	planetID        int
+	dockingProgress float64
+	weaponCooldown  float64
+	dead            bool
+}
+
+func (s *ship) Position() (x, y float64) {
+	return s.x, s.y
+}
+
+func (s *ship) Circle() (x, y, radius float64) {
+	return s.x, s.y, s.radius
+}
+
+type planet struct {
+	id                 int
+	x, y               float64
+	radius             float64
+	health             float64
+	dockingSpots       float64
+	production         float64
+	remainingResources float64
+	owned              bool
+	owner              int
+	docked             []int
+	dead               bool
+}
+
+func (p *planet) Position() (x, y float64) {
+	return p.x, p.y
+}
+
+func (p *planet) Circle() (x, y, radius float64) {
+	return p.x, p.y, p.radius
+}
+
+// New creates a simulator that starts from the given game state
+func New(gameMap hlt.Map) *Simulator {
+	s := &Simulator{
+		Width:   gameMap.Width,
+		Height:  gameMap.Height,
+		players: len(gameMap.Players),
+	}
+
+	for _, player := range gameMap.Players {
+		for _, hltShip := range player.Ships {
+			x, y, radius := hltShip.Circle()
+			s.ships = append(s.ships, &ship{
+				id:              hltShip.ID(),
+				owner:           player.ID,
+				x:               x,
+				y:               y,
+				radius:          radius,
+				health:          hltShip.Health(),
+				velX:            hltShip.VelX,
+				velY:            hltShip.VelY,
+				dockingStatus:   hltShip.DockingStatus,
+				planetID:        hltShip.PlanetID,
+				dockingProgress: hltShip.DockingProgress,
+				weaponCooldown:  hltShip.WeaponCooldown,
+			})
+			if hltShip.ID() >= s.nextShipID {
+				s.nextShipID = hltShip.ID() + 1
+			}
+		}
+	}
+	sort.Slice(s.ships, func(i, j int) bool { return s.ships[i].id < s.ships[j].id })
+
+	for _, hltPlanet := range gameMap.Planets {
+		x, y, radius := hltPlanet.Circle()
+		s.planets = append(s.planets, &planet{
+			id:                 hltPlanet.ID(),
+			x:                  x,
+			y:                  y,
+			radius:             radius,
+			health:             hltPlanet.Health(),
+			dockingSpots:       hltPlanet.NumDockingSpots,
+			production:         hltPlanet.CurrentProduction,
+			remainingResources: hltPlanet.RemainingResources,
+			owned:              hltPlanet.Owned != 0,
+			owner:              hltPlanet.Owner(),
+			docked:             append([]int(nil), hltPlanet.DockedShipIDs...),
+		})
+	}
+	return s
+}
+
+// Map returns the current game state as seen by the given player
+func (s *Simulator) Map(playerID int) hlt.Map {
+	gameMap := hlt.Map{
+		MyID:     playerID,
+		Width:    s.Width,
+		Height:   s.Height,
+		Planets:  make([]hlt.Planet, 0, len(s.planets)),
+		Players:  make([]hlt.Player, s.players),
+		Ships:    make(map[int]hlt.Ship),
+		Entities: make([]hlt.Entitier, 0, len(s.ships)+len(s.planets)),
+	}
+
+	for i := range gameMap.Players {
+		gameMap.Players[i] = hlt.Player{
+			ID:    i,
+			Ships: []hlt.Ship{},
+		}
+	}
+	for _, sh := range s.ships {
+		hltShip := hlt.Ship{
+			Entity:          hlt.NewEntity(sh.x, sh.y, sh.radius, sh.health, sh.owner, sh.id),
+			VelX:            sh.velX,
+			VelY:            sh.velY,
+			PlanetID:        sh.planetID,
+			DockingStatus:   sh.dockingStatus,
+			DockingProgress: sh.dockingProgress,
+			WeaponCooldown:  sh.weaponCooldown,
+		}
+		player := &gameMap.Players[sh.owner]
+		player.Ships = append(player.Ships, hltShip)
+	}
+	for _, player := range gameMap.Players {
+		for _, hltShip := range player.Ships {
+			gameMap.Entities = append(gameMap.Entities, hltShip.Entity)
+			gameMap.Ships[hltShip.ID()] = hltShip
+		}
+	}
+
+	for _, p := range s.planets {
+		owned := 0.0
+		if p.owned {
+			owned = 1
+		}
+		hltPlanet := hlt.Planet{
+			Entity:             hlt.NewEntity(p.x, p.y, p.radius, p.health, p.owner, p.id),
+			NumDockingSpots:    p.dockingSpots,
+			NumDockedShips:     float64(len(p.docked)),
+			CurrentProduction:  p.production,
+			RemainingResources: p.remainingResources,
+			DockedShipIDs:      append([]int(nil), p.docked...),
+			Owned:              owned,
+		}
+		gameMap.Planets = append(gameMap.Planets, hltPlanet)
+		gameMap.Entities = append(gameMap.Entities, hltPlanet.Entity)
+	}
+	return gameMap
+}
+
+// ShipCount returns the number of ships alive for the given player
+func (s *Simulator) ShipCount(playerID int) int {
+	count := 0
+	for _, sh := range s.ships {
+		if sh.owner == playerID {
+			count++
+		}
+	}
+	return count
+}
+
+// Finished reports if the game is over, either because only one player has ships or the turn limit was reached
+func (s *Simulator) Finished() bool {
+	if s.Turn >= hlt.Constants.MaxTurns {
+		return true
+	}
+	alive := 0
+	for player := 0; player < s.players; player++ {
+		if s.ShipCount(player) > 0 {
+			alive++
+		}
+	}
+	return alive <= 1
+}
+
+// Winner returns the player with more ships, ties are solved by total health.
+// It returns -1 if nobody has ships
+func (s *Simulator) Winner() int {
+	winner := -1
+	bestCount, bestHealth := 0, 0.0
+	for player := 0; player < s.players; player++ {
+		count, health := 0, 0.0
+		for _, sh := range s.ships {
+			if sh.owner == player {
+				count++
+				health += sh.health
+			}
+		}
+		if count > bestCount || (count == bestCount && count > 0 && health > bestHealth) {
+			winner, bestCount, bestHealth = player, count, health
+		}
+	}
+	return winner
+}
+
+// Bot computes the commands for a player given the game state from its point of view
+type Bot func(gameMap hlt.Map) []string
+
+// Play runs turns until the game is finished and returns the winner.
+// The bot at index i plays as player i
+func (s *Simulator) Play(bots ...Bot) (int, error) {
+	for !s.Finished() {
+		commands := make([][]string, len(bots))
+		for player, bot := range bots {
+			if s.ShipCount(player) == 0 {
+				continue
+			}
+			commands[player] = bot(s.Map(player))
+		}
+		if err := s.Step(commands...); err != nil {
+			return -1, err
+		}
+	}
+	return s.Winner(), nil
+}
+
+func (s *Simulator) ship(id int) *ship {
+	index := sort.Search(len(s.ships), func(i int) bool { return s.ships[i].id >= id })
+	if index < len(s.ships) && s.ships[index].id == id {
+		return s.ships[index]
+	}
+	return nil
+}
+
+func (s *Simulator) planet(id int) *planet {
+	for _, p := range s.planets {
+		if p.id == id {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package simulator_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	. "github.com/metalblueberry/halite-bot/pkg/simulator"
+)
+
+func newShip(owner, id int, x, y float64) hlt.Ship {
+	return hlt.Ship{
+		Entity: hlt.NewEntity(x, y, 0.5, 255, owner, id),
+	}
+}
+
+func newPlanet(id int, x, y, radius float64) hlt.Planet {
+	return hlt.Planet{
+		Entity:          hlt.NewEntity(x, y, radius, radius*255, 0, id),
+		NumDockingSpots: 2,
+	}
+}
+
+func newMap(players int, ships []hlt.Ship, planets []hlt.Planet) hlt.Map {
+	gameMap := hlt.Map{
+		Width:   100,
+		Height:  100,
+		Players: make([]hlt.Player, players),
+		Planets: planets,
+	}
+	for i := range gameMap.Players {
+		gameMap.Players[i].ID = i
+	}
+	for _, ship := range ships {
+		player := &gameMap.Players[ship.Owner()]
+		player.Ships = append(player.Ships, ship)
+	}
+	return gameMap
+}
+
+var _ = Describe("Simulator", func() {
+	Describe("Moving ships", func() {
+		It("Should apply thrust commands", func() {
+			sim := New(newMap(1, []hlt.Ship{newShip(0, 0, 10, 10)}, nil))
+			Expect(sim.Step([]string{"t 0 7 90"})).To(Succeed())
+
+			ship := sim.Map(0).Ships[0]
+			x, y := ship.Position()
+			Expect(x).To(BeNumerically("~", 10, 0.001))
+			Expect(y).To(BeNumerically("~", 17, 0.001))
+			Expect(ship.VelX).To(BeNumerically("==", 0))
+		})
+		It("Should destroy ships that collide", func() {
+			sim := New(newMap(2, []hlt.Ship{newShip(0, 0, 10, 10), newShip(1, 1, 16, 10)}, nil))
+			sim.Step([]string{"t 0 3 0"}, []string{"t 1 3 180"})
+
+			Expect(sim.Map(0).Ships).To(BeEmpty())
+		})
+		It("Should destroy ships that crash into a planet and damage it", func() {
+			sim := New(newMap(1, []hlt.Ship{newShip(0, 0, 12, 10)}, []hlt.Planet{newPlanet(0, 20, 10, 5)}))
+			sim.Step([]string{"t 0 7 0"})
+
+			gameMap := sim.Map(0)
+			Expect(gameMap.Ships).To(BeEmpty())
+			Expect(gameMap.Planets[0].Health()).To(BeNumerically("==", 5*255-255))
+		})
+		It("Should destroy ships that leave the map", func() {
+			sim := New(newMap(1, []hlt.Ship{newShip(0, 0, 2, 10)}, nil))
+			sim.Step([]string{"t 0 7 180"})
+
+			Expect(sim.Map(0).Ships).To(BeEmpty())
+		})
+		It("Should ignore commands for ships owned by other players", func() {
+			sim := New(newMap(2, []hlt.Ship{newShip(0, 0, 10, 10), newShip(1, 1, 50, 50)}, nil))
+			sim.Step([]string{"t 1 7 0"}, nil)
+
+			x, _ := sim.Map(0).Ships[1].Position()
+			Expect(x).To(BeNumerically("==", 50))
+		})
+		It("Should reject malformed commands", func() {
+			sim := New(newMap(1, []hlt.Ship{newShip(0, 0, 10, 10)}, nil))
+			Expect(sim.Step([]string{"t 0 7"})).ToNot(Succeed())
+			Expect(sim.Step([]string{"x 0"})).ToNot(Succeed())
+		})
+	})
+	Describe("Fighting", func() {
+		It("Should split the damage between all targets in range", func() {
+			sim := New(newMap(2, []hlt.Ship{
+				newShip(0, 0, 10, 10),
+				newShip(1, 1, 14, 10),
+				newShip(1, 2, 10, 14),
+			}, nil))
+			sim.Step(nil, nil)
+
+			ships := sim.Map(0).Ships
+			Expect(ships[0].Health()).To(BeNumerically("==", 255-2*64))
+			Expect(ships[1].Health()).To(BeNumerically("==", 255-32))
+			Expect(ships[2].Health()).To(BeNumerically("==", 255-32))
+			Expect(ships[0].WeaponCooldown).To(BeNumerically("==", 1))
+		})
+		It("Should not fire from docked ships", func() {
+			docked := newShip(0, 0, 10, 10)
+			docked.DockingStatus = hlt.DOCKED
+			docked.PlanetID = 0
+			planet := newPlanet(0, 10, 4, 5)
+			planet.Owned = 1
+			planet.NumDockedShips = 1
+			planet.DockedShipIDs = []int{0}
+			sim := New(newMap(2, []hlt.Ship{docked, newShip(1, 1, 14, 10)}, []hlt.Planet{planet}))
+			sim.Step(nil, nil)
+
+			ships := sim.Map(0).Ships
+			Expect(ships[0].Health()).To(BeNumerically("==", 255-64))
+			Expect(ships[1].Health()).To(BeNumerically("==", 255))
+		})
+	})
+	Describe("Docking", func() {
+		It("Should dock after DOCK_TURNS and produce ships", func() {
+			sim := New(newMap(1, []hlt.Ship{newShip(0, 0, 12, 10)}, []hlt.Planet{newPlanet(0, 20, 10, 5)}))
+			sim.Step([]string{"d 0 0"})
+
+			gameMap := sim.Map(0)
+			Expect(gameMap.Ships[0].DockingStatus).To(Equal(hlt.DOCKING))
+			Expect(gameMap.Planets[0].Owned).To(BeNumerically("==", 1))
+			Expect(gameMap.Planets[0].DockedShipIDs).To(Equal([]int{0}))
+
+			for i := 1; i < hlt.Constants.DockTurns; i++ {
+				sim.Step(nil)
+			}
+			Expect(sim.Map(0).Ships[0].DockingStatus).To(Equal(hlt.DOCKED))
+
+			turns := hlt.Constants.ProductionPerShip / hlt.Constants.BaseProductivity
+			for i := 0; i < int(turns); i++ {
+				sim.Step(nil)
+			}
+			Expect(sim.ShipCount(0)).To(Equal(2))
+		})
+		It("Should not dock when players contest a neutral planet", func() {
+			sim := New(newMap(2, []hlt.Ship{newShip(0, 0, 12, 10), newShip(1, 1, 28, 10)}, []hlt.Planet{newPlanet(0, 20, 10, 5)}))
+			sim.Step([]string{"d 0 0"}, []string{"d 1 0"})
+
+			gameMap := sim.Map(0)
+			Expect(gameMap.Planets[0].Owned).To(BeNumerically("==", 0))
+			Expect(gameMap.Ships[0].DockingStatus).To(Equal(hlt.UNDOCKED))
+		})
+		It("Should free the planet after undocking", func() {
+			sim := New(newMap(1, []hlt.Ship{newShip(0, 0, 12, 10)}, []hlt.Planet{newPlanet(0, 20, 10, 5)}))
+			sim.Step([]string{"d 0 0"})
+			for i := 0; i < 5; i++ {
+				sim.Step(nil)
+			}
+			sim.Step([]string{"u 0"})
+			for i := 0; i < 5; i++ {
+				sim.Step(nil)
+			}
+
+			gameMap := sim.Map(0)
+			Expect(gameMap.Ships[0].DockingStatus).To(Equal(hlt.UNDOCKED))
+			Expect(gameMap.Planets[0].Owned).To(BeNumerically("==", 0))
+		})
+	})
+	Describe("Planet explosions", func() {
+		It("Should damage ships around the planet", func() {
+			planet := newPlanet(0, 20, 10, 1)
+			sim := New(newMap(1, []hlt.Ship{newShip(0, 0, 12, 10), newShip(0, 1, 22, 10), newShip(0, 2, 20, 40)}, []hlt.Planet{planet}))
+			sim.Step([]string{"t 0 7 0"})
+
+			gameMap := sim.Map(0)
+			Expect(gameMap.Planets).To(BeEmpty())
+			Expect(gameMap.Ships).ToNot(HaveKey(0))
+			Expect(gameMap.Ships[1].Health()).To(BeNumerically("<", 255))
+			Expect(gameMap.Ships[2].Health()).To(BeNumerically("==", 255))
+		})
+	})
+	Describe("Encoding the game", func() {
+		It("Should produce game strings that the bot can parse", func() {
+			planet := newPlanet(0, 20, 10, 5)
+			sim := New(newMap(2, []hlt.Ship{newShip(0, 0, 12, 10), newShip(1, 1, 50, 50)}, []hlt.Planet{planet}))
+			sim.Step([]string{"d 0 0"}, nil)
+
+			gameMap, err := hlt.ParseGameStringStrict(&hlt.Connection{}, sim.GameString())
+			Expect(err).ToNot(HaveOccurred())
+			gameMap.Width, gameMap.Height = 100, 100
+			Expect(gameMap).To(Equal(sim.Map(0)))
+		})
+	})
+	Describe("Playing games", func() {
+		It("Should generate a map for every player", func() {
+			gameMap := Generate(240, 160, 4, 1)
+			Expect(gameMap.Players).To(HaveLen(4))
+			for _, player := range gameMap.Players {
+				Expect(player.Ships).To(HaveLen(3))
+			}
+			Expect(len(gameMap.Planets) % 4).To(Equal(1))
+		})
+		It("Should play until there is a winner", func() {
+			sim := New(Generate(240, 160, 2, 1))
+			idle := func(gameMap hlt.Map) []string { return nil }
+			kamikaze := func(gameMap hlt.Map) []string {
+				commands := []string{}
+				enemies := gameMap.Players[1-gameMap.MyID].Ships
+				for _, ship := range gameMap.Players[gameMap.MyID].Ships {
+					if len(enemies) == 0 {
+						break
+					}
+					enemy := enemies[0]
+					commands = append(commands, ship.NavigateBasic(enemy).String())
+				}
+				return commands
+			}
+
+			winner, err := sim.Play(idle, kamikaze)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sim.Finished()).To(BeTrue())
+			Expect(winner).To(BeNumerically(">=", -1))
+		})
+	})
+})
@@ -0,0 +1,462 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/metalblueberry/halite-bot/pkg/hlt"
+	"github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+// Step advances the game one turn. commands[i] holds the commands sent by player i.
+// Commands that the engine would ignore, like thrusting a docked ship, are discarded,
+// but malformed commands return an error.
+func (s *Simulator) Step(commands ...[]string) error {
+	moves := make(map[int]command)
+	for player, raw := range commands {
+		parsed, err := parseCommands(raw)
+		if err != nil {
+			return fmt.Errorf("player %d: %v", player, err)
+		}
+		for _, c := range parsed {
+			sh := s.ship(c.ShipID)
+			if sh == nil || sh.owner != player {
+				continue
+			}
+			if _, exist := moves[c.ShipID]; exist {
+				continue
+			}
+			moves[c.ShipID] = c
+		}
+	}
+
+	s.processCommands(moves)
+	s.processDocking()
+	s.processProduction()
+	s.processMovement()
+	s.processDrag()
+	s.removeDead()
+	s.Turn++
+	return nil
+}
+
+func (s *Simulator) processCommands(moves map[int]command) {
+	// Unowned planets only accept ships from a single player, if several
+	// players try to dock the same turn nobody gets it
+	contested := make(map[int][]*ship)
+
+	for _, sh := range s.ships {
+		c, exist := moves[sh.id]
+		if !exist {
+			continue
+		}
+		switch c.Type {
+		case thrustCommand:
+			if sh.dockingStatus != hlt.UNDOCKED {
+				continue
+			}
+			angle := twoD.DegToRad(float64(c.Angle))
+			sh.velX += float64(c.Magnitude) * math.Cos(angle)
+			sh.velY += float64(c.Magnitude) * math.Sin(angle)
+			speed := math.Hypot(sh.velX, sh.velY)
+			if maxSpeed := hlt.Constants.MaxSpeed; speed > maxSpeed {
+				sh.velX *= maxSpeed / speed
+				sh.velY *= maxSpeed / speed
+			}
+		case dockCommand:
+			p := s.planet(c.PlanetID)
+			if p == nil || sh.dockingStatus != hlt.UNDOCKED {
+				continue
+			}
+			if twoD.Distance(sh, p) > sh.radius+p.radius+hlt.Constants.DockRadius {
+				continue
+			}
+			if !p.owned {
+				contested[p.id] = append(contested[p.id], sh)
+				continue
+			}
+			if p.owner == sh.owner && float64(len(p.docked)) < p.dockingSpots {
+				s.dock(sh, p)
+			}
+		case undockCommand:
+			if sh.dockingStatus != hlt.DOCKED {
+				continue
+			}
+			sh.dockingStatus = hlt.UNDOCKING
+			sh.dockingProgress = float64(hlt.Constants.DockTurns)
+		}
+	}
+
+	for planetID, ships := range contested {
+		owner := ships[0].owner
+		single := true
+		for _, sh := range ships {
+			if sh.owner != owner {
+				single = false
+			}
+		}
+		if !single {
+			continue
+		}
+		p := s.planet(planetID)
+		p.owned = true
+		p.owner = owner
+		for _, sh := range ships {
+			if float64(len(p.docked)) < p.dockingSpots {
+				s.dock(sh, p)
+			}
+		}
+	}
+}
+
+func (s *Simulator) dock(sh *ship, p *planet) {
+	sh.dockingStatus = hlt.DOCKING
+	sh.dockingProgress = float64(hlt.Constants.DockTurns)
+	sh.planetID = p.id
+	sh.velX, sh.velY = 0, 0
+	p.docked = append(p.docked, sh.id)
+}
+
+func (s *Simulator) processDocking() {
+	for _, sh := range s.ships {
+		switch sh.dockingStatus {
+		case hlt.DOCKING:
+			sh.dockingProgress--
+			if sh.dockingProgress <= 0 {
+				sh.dockingProgress = 0
+				sh.dockingStatus = hlt.DOCKED
+			}
+		case hlt.DOCKED:
+			sh.health = math.Min(sh.health+hlt.Constants.DockedShipRegeneration, hlt.Constants.MaxShipHealth)
+		case hlt.UNDOCKING:
+			sh.dockingProgress--
+			if sh.dockingProgress <= 0 {
+				s.release(sh)
+			}
+		}
+	}
+}
+
+// release detaches a ship from its planet, the planet becomes neutral when no ships are left
+func (s *Simulator) release(sh *ship) {
+	if p := s.planet(sh.planetID); p != nil {
+		for i, id := range p.docked {
+			if id == sh.id {
+				p.docked = append(p.docked[:i], p.docked[i+1:]...)
+				break
+			}
+		}
+		if len(p.docked) == 0 {
+			p.owned = false
+			p.owner = 0
+		}
+	}
+	sh.dockingStatus = hlt.UNDOCKED
+	sh.dockingProgress = 0
+	sh.planetID = 0
+}
+
+func (s *Simulator) processProduction() {
+	infinite := hlt.Constants.InfiniteResources
+	for _, p := range s.planets {
+		if !p.owned {
+			continue
+		}
+		docked := 0
+		for _, id := range p.docked {
+			if sh := s.ship(id); sh != nil && sh.dockingStatus == hlt.DOCKED {
+				docked++
+			}
+		}
+		if docked == 0 {
+			continue
+		}
+
+		production := hlt.Constants.BaseProductivity + hlt.Constants.AdditionalProductivity*float64(docked-1)
+		if !infinite {
+			production = math.Min(production, p.remainingResources)
+			p.remainingResources -= production
+		}
+		p.production += production
+
+		for p.production >= hlt.Constants.ProductionPerShip {
+			if !s.spawn(p) {
+				break
+			}
+			p.production -= hlt.Constants.ProductionPerShip
+		}
+	}
+}
+
+// spawn places a new ship next to the planet, facing the map center when possible
+func (s *Simulator) spawn(p *planet) bool {
+	radius := hlt.Constants.ShipRadius
+	center := twoD.NewPosition(float64(s.Width)/2, float64(s.Height)/2)
+	base := twoD.CalculateRadAngleTo(p, center)
+	dist := p.radius + hlt.Constants.SpawnRadius
+
+	for attempt := 0; attempt < 36; attempt++ {
+		// try alternatively at both sides of the preferred direction
+		offset := float64((attempt+1)/2) * twoD.DegToRad(10)
+		if attempt%2 == 1 {
+			offset = -offset
+		}
+		x := p.x + dist*math.Cos(base+offset)
+		y := p.y + dist*math.Sin(base+offset)
+		if !s.free(x, y, radius) {
+			continue
+		}
+		s.ships = append(s.ships, &ship{
+			id:     s.nextShipID,
+			owner:  p.owner,
+			x:      x,
+			y:      y,
+			radius: radius,
+			health: hlt.Constants.BaseShipHealth,
+		})
+		s.nextShipID++
+		return true
+	}
+	return false
+}
+
+func (s *Simulator) free(x, y, radius float64) bool {
+	if x-radius < 0 || y-radius < 0 || x+radius >= float64(s.Width) || y+radius >= float64(s.Height) {
+		return false
+	}
+	position := twoD.NewPosition(x, y)
+	for _, sh := range s.ships {
+		if twoD.Distance(sh, position) <= sh.radius+radius {
+			return false
+		}
+	}
+	for _, p := range s.planets {
+		if twoD.Distance(p, position) <= p.radius+radius {
+			return false
+		}
+	}
+	return true
+}
+
+type eventType int
+
+const (
+	shipCollision eventType = iota
+	planetCollision
+	attack
+)
+
+type event struct {
+	Type   eventType
+	Time   float64
+	Ship   *ship
+	Other  *ship
+	Planet *planet
+}
+
+// processMovement moves all ships at the same time and resolves collisions and
+// attacks in the order they happen during the turn
+func (s *Simulator) processMovement() {
+	for _, sh := range s.ships {
+		sh.weaponCooldown = math.Max(sh.weaponCooldown-1, 0)
+	}
+
+	events := s.findEvents()
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+
+	for len(events) > 0 {
+		// Events at the same time happen simultaneously
+		end := 1
+		for end < len(events) && events[end].Time-events[0].Time < 1e-9 {
+			end++
+		}
+		s.resolveEvents(events[:end])
+		events = events[end:]
+	}
+
+	for _, sh := range s.ships {
+		if sh.dead {
+			continue
+		}
+		sh.x += sh.velX
+		sh.y += sh.velY
+		if sh.x < 0 || sh.y < 0 || sh.x >= float64(s.Width) || sh.y >= float64(s.Height) {
+			s.kill(sh)
+		}
+	}
+}
+
+func (s *Simulator) findEvents() []event {
+	weaponRadius := hlt.Constants.WeaponRadius
+	events := []event{}
+	for i, sh := range s.ships {
+		for _, other := range s.ships[i+1:] {
+			if t, ok := collisionTime(sh, other, sh.radius+other.radius); ok {
+				events = append(events, event{Type: shipCollision, Time: t, Ship: sh, Other: other})
+			}
+			if sh.owner == other.owner {
+				continue
+			}
+			if t, ok := collisionTime(sh, other, sh.radius+other.radius+weaponRadius); ok {
+				events = append(events, event{Type: attack, Time: t, Ship: sh, Other: other})
+			}
+		}
+		for _, p := range s.planets {
+			if sh.dockingStatus != hlt.UNDOCKED {
+				continue
+			}
+			if t, ok := collisionTime(sh, &ship{x: p.x, y: p.y}, sh.radius+p.radius); ok {
+				events = append(events, event{Type: planetCollision, Time: t, Ship: sh, Planet: p})
+			}
+		}
+	}
+	return events
+}
+
+// collisionTime returns the first moment in the turn at which two moving ships are at the given distance
+func collisionTime(a, b *ship, distance float64) (float64, bool) {
+	return twoD.CollisionTime(
+		twoD.SweptCircle{X: a.x, Y: a.y, VelX: a.velX, VelY: a.velY},
+		twoD.SweptCircle{X: b.x, Y: b.y, R: distance, VelX: b.velX, VelY: b.velY},
+	)
+}
+
+func (s *Simulator) resolveEvents(events []event) {
+	targets := make(map[*ship][]*ship)
+	addTarget := func(attacker, target *ship) {
+		if attacker.dockingStatus != hlt.UNDOCKED || attacker.weaponCooldown > 0 {
+			return
+		}
+		targets[attacker] = append(targets[attacker], target)
+	}
+
+	for _, e := range events {
+		if e.Ship.dead {
+			continue
+		}
+		switch e.Type {
+		case shipCollision:
+			if e.Other.dead {
+				continue
+			}
+			s.kill(e.Ship)
+			s.kill(e.Other)
+		case planetCollision:
+			if e.Planet.dead {
+				continue
+			}
+			e.Planet.health -= e.Ship.health
+			s.kill(e.Ship)
+		case attack:
+			if e.Other.dead {
+				continue
+			}
+			addTarget(e.Ship, e.Other)
+			addTarget(e.Other, e.Ship)
+		}
+	}
+
+	damage := hlt.Constants.WeaponDamage
+	for attacker, list := range targets {
+		for _, target := range list {
+			target.health -= damage / float64(len(list))
+		}
+		attacker.weaponCooldown = float64(hlt.Constants.WeaponCooldown)
+	}
+
+	time := events[0].Time
+	for _, sh := range s.ships {
+		if !sh.dead && sh.health <= 0 {
+			s.kill(sh)
+		}
+	}
+	s.explodePlanets(time)
+}
+
+// explodePlanets destroys planets without health, damaging everything around them.
+// Damage decreases linearly with the distance to the planet surface
+func (s *Simulator) explodePlanets(time float64) {
+	radius := hlt.Constants.ExplosionRadius
+	maxDamage := hlt.Constants.MaxShipHealth
+	for exploded := true; exploded; {
+		exploded = false
+		for _, p := range s.planets {
+			if p.dead || p.health > 0 {
+				continue
+			}
+			exploded = true
+			p.dead = true
+
+			for _, sh := range s.ships {
+				if sh.dead {
+					continue
+				}
+				position := twoD.NewPosition(sh.x+sh.velX*time, sh.y+sh.velY*time)
+				surface := twoD.Distance(p, position) - p.radius - sh.radius
+				if surface < radius {
+					sh.health -= maxDamage * (1 - math.Max(surface, 0)/radius)
+				}
+				if sh.planetID == p.id && sh.dockingStatus != hlt.UNDOCKED {
+					sh.dockingStatus = hlt.UNDOCKED
+					sh.dockingProgress = 0
+					sh.planetID = 0
+				}
+				if sh.health <= 0 {
+					s.kill(sh)
+				}
+			}
+			for _, other := range s.planets {
+				if other.dead {
+					continue
+				}
+				surface := twoD.Distance(p, other) - p.radius - other.radius
+				if surface < radius {
+					other.health -= maxDamage * (1 - math.Max(surface, 0)/radius)
+				}
+			}
+		}
+	}
+}
+
+func (s *Simulator) kill(sh *ship) {
+	if sh.dead {
+		return
+	}
+	if sh.dockingStatus != hlt.UNDOCKED {
+		s.release(sh)
+	}
+	sh.dead = true
+	sh.health = 0
+}
+
+func (s *Simulator) processDrag() {
+	drag := hlt.Constants.Drag
+	for _, sh := range s.ships {
+		speed := math.Hypot(sh.velX, sh.velY)
+		if speed <= drag {
+			sh.velX, sh.velY = 0, 0
+			continue
+		}
+		sh.velX -= drag * sh.velX / speed
+		sh.velY -= drag * sh.velY / speed
+	}
+}
+
+func (s *Simulator) removeDead() {
+	ships := s.ships[:0]
+	for _, sh := range s.ships {
+		if !sh.dead {
+			ships = append(ships, sh)
+		}
+	}
+	s.ships = ships
+
+	planets := s.planets[:0]
+	for _, p := range s.planets {
+		if !p.dead {
+			planets = append(planets, p)
+		}
+	}
+	s.planets = planets
+}
@@ -0,0 +1,57 @@
+package twoD
+
+import "math"
+
+// SweptCircle is a circle that moves in straight line during a turn.
+// At time t, in the unit interval, its center is at X+t*VelX, Y+t*VelY
+type SweptCircle struct {
+	X, Y, R    float64
+	VelX, VelY float64
+}
+
+// NewSweptCircle creates a SweptCircle that starts at circle and moves velX, velY during the turn
+func NewSweptCircle(circle Circler, velX, velY float64) SweptCircle {
+	x, y, r := circle.Circle()
+	return SweptCircle{X: x, Y: y, R: r, VelX: velX, VelY: velY}
+}
+
+func (c SweptCircle) Position() (x, y float64) {
+	return c.X, c.Y
+}
+
+func (c SweptCircle) Circle() (x, y, r float64) {
+	return c.X, c.Y, c.R
+}
+
+// At returns the center of the circle at time t
+func (c SweptCircle) At(t float64) Positioner {
+	return NewPosition(c.X+t*c.VelX, c.Y+t*c.VelY)
+}
+
+// CollisionTime returns the first moment of the unit interval at which the circles touch.
+// Circles that already overlap collide at 0
+func CollisionTime(a, b SweptCircle) (float64, bool) {
+	distance := a.R + b.R
+	dx, dy := b.X-a.X, b.Y-a.Y
+	dvx, dvy := b.VelX-a.VelX, b.VelY-a.VelY
+
+	// Solve |d + t*dv| = distance
+	c := dx*dx + dy*dy - distance*distance
+	if c <= 0 {
+		return 0, true
+	}
+	aa := dvx*dvx + dvy*dvy
+	if aa == 0 {
+		return 0, false
+	}
+	bb := 2 * (dx*dvx + dy*dvy)
+	disc := bb*bb - 4*aa*c
+	if disc < 0 {
+		return 0, false
+	}
+	t := (-bb - math.Sqrt(disc)) / (2 * aa)
+	if t < 0 || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
@@ -0,0 +1,45 @@
+package twoD_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/metalblueberry/halite-bot/pkg/twoD"
+)
+
+var _ = Describe("SweptCircle", func() {
+	It("Should find the collision of ships moving against each other", func() {
+		a := SweptCircle{X: 0, Y: 0, R: 0.5, VelX: 4}
+		b := SweptCircle{X: 8, Y: 0, R: 0.5, VelX: -4}
+		t, collide := CollisionTime(a, b)
+		Expect(collide).To(BeTrue())
+		Expect(t).To(BeNumerically("~", 7.0/8.0, 0.001))
+	})
+	It("Should find crossing paths", func() {
+		a := SweptCircle{X: 0, Y: 0, R: 0.5, VelX: 7}
+		b := SweptCircle{X: 3.5, Y: -3.5, R: 0.5, VelY: 7}
+		t, collide := CollisionTime(a, b)
+		Expect(collide).To(BeTrue())
+		Expect(t).To(BeNumerically("<", 0.5))
+		Expect(Distance(a.At(t), b.At(t))).To(BeNumerically("~", 1, 0.001))
+	})
+	It("Should ignore collisions after the turn", func() {
+		a := SweptCircle{X: 0, Y: 0, R: 0.5, VelX: 1}
+		b := SweptCircle{X: 10, Y: 0, R: 0.5, VelX: -1}
+		_, collide := CollisionTime(a, b)
+		Expect(collide).To(BeFalse())
+	})
+	It("Should ignore ships moving in parallel", func() {
+		a := SweptCircle{X: 0, Y: 0, R: 0.5, VelX: 7}
+		b := SweptCircle{X: 0, Y: 2, R: 0.5, VelX: 7}
+		_, collide := CollisionTime(a, b)
+		Expect(collide).To(BeFalse())
+	})
+	It("Should report overlapping circles at the start of the turn", func() {
+		a := SweptCircle{X: 0, Y: 0, R: 0.5}
+		b := NewSweptCircle(SweptCircle{X: 0.5, Y: 0, R: 0.5}, 0, 0)
+		t, collide := CollisionTime(a, b)
+		Expect(collide).To(BeTrue())
+		Expect(t).To(BeZero())
+	})
+})